@@ -0,0 +1,120 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package deepmerge
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lablabs/terraform-provider-lara-utils/internal/helpers"
+)
+
+// canonicalKey returns a string identifying the value, equal for equal values and different for values of
+// different types, so values can be compared using maps instead of pairwise comparisons.
+func canonicalKey(v any) string {
+	var sb strings.Builder
+	writeCanonical(&sb, v)
+	return sb.String()
+}
+
+func writeCanonical(sb *strings.Builder, v any) {
+	switch vv := v.(type) {
+	case nil:
+		sb.WriteByte('n')
+	case bool:
+		if vv {
+			sb.WriteByte('t')
+		} else {
+			sb.WriteByte('f')
+		}
+	case *big.Float, float64:
+		n, _ := number(vv)
+		sb.WriteByte('d')
+		if n.Sign() == 0 {
+			sb.WriteByte('0') // -0 equals 0
+		} else {
+			sb.WriteString(n.Text('p', 0)) // exact, independent of the precision
+		}
+		sb.WriteByte(';')
+	case string:
+		writeCanonicalString(sb, 's', vv)
+	case []any:
+		sb.WriteByte('l')
+		sb.WriteString(strconv.Itoa(len(vv)))
+		sb.WriteByte(':')
+		for _, elem := range vv {
+			writeCanonical(sb, elem)
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(vv))
+		for key := range vv {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+
+		sb.WriteByte('m')
+		sb.WriteString(strconv.Itoa(len(vv)))
+		sb.WriteByte(':')
+		for _, key := range keys {
+			writeCanonicalString(sb, 'k', key)
+			writeCanonical(sb, vv[key])
+		}
+	case *helpers.Unknown:
+		writeCanonicalString(sb, 'u', fmt.Sprintf("%p", vv)) // unknown values never equal each other
+	default:
+		writeCanonicalString(sb, '?', fmt.Sprintf("%T:%v", v, v))
+	}
+}
+
+// writeCanonicalString writes the string prefixed by its length, so it can't be confused with following values.
+func writeCanonicalString(sb *strings.Builder, tag byte, s string) {
+	sb.WriteByte(tag)
+	sb.WriteString(strconv.Itoa(len(s)))
+	sb.WriteByte(':')
+	sb.WriteString(s)
+}
+
+// equalValues reports whether the values are deeply equal.
+func equalValues(a, b any) bool {
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for key, elem := range av {
+			other, ok := bv[key]
+			if !ok || !equalValues(elem, other) {
+				return false
+			}
+		}
+		return true
+
+	case []any:
+		bv, ok := b.([]any)
+		return ok && slices.EqualFunc(av, bv, equalValues)
+	}
+
+	if an, ok := number(a); ok {
+		bn, ok := number(b)
+		return ok && an.Cmp(bn) == 0
+	}
+
+	return a == b
+}
+
+// number returns the value if it's a number. Numbers are big floats as encoded from Terraform values, so large
+// integers aren't corrupted, float64 values are accepted as well.
+func number(v any) (*big.Float, bool) {
+	switch vv := v.(type) {
+	case *big.Float:
+		return vv, true
+	case float64:
+		return big.NewFloat(vv), true
+	}
+	return nil, false
+}
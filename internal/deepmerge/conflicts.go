@@ -0,0 +1,174 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package deepmerge
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/lablabs/terraform-provider-lara-utils/internal/helpers"
+	"github.com/lablabs/terraform-provider-lara-utils/internal/pathexpr"
+)
+
+// Values of the on_conflict option, controlling what happens when merged objects set the same value differently.
+const (
+	OnConflictOverride  = "override"
+	OnConflictKeepFirst = "keep_first"
+	OnConflictError     = "error"
+	OnConflictWarn      = "warn"
+)
+
+// mergeState is shared by all mergers used during a single merge.
+type mergeState struct {
+	// origins holds the index of the merged object which set the value at the path, if tracked.
+	origins map[string]int
+	// conflicts holds the conflicting paths.
+	conflicts []*mergeConflict
+	// conflictsByPath indexes conflicts by their path.
+	conflictsByPath map[string]*mergeConflict
+	// mismatches holds descriptions of values of different types found at the same path.
+	mismatches []string
+	// provenance records the histories of the merged values, if explained.
+	provenance *provenance
+}
+
+// mergeConflict describes merged objects setting different values at the same path.
+type mergeConflict struct {
+	path string
+	mode string
+	args []int
+}
+
+// newMergeState returns a new state, tracking origins of the merged values only if needed by the options.
+func newMergeState(trackOrigins bool) *mergeState {
+	s := &mergeState{conflictsByPath: map[string]*mergeConflict{}}
+	if trackOrigins {
+		s.origins = map[string]int{}
+	}
+	return s
+}
+
+// tracksOrigins reports whether conflicts or type mismatches are reported, globally or by path options,
+// which requires tracking the origins of the merged values.
+func (opts DeepMergeOptions) tracksOrigins() bool {
+	reports := func(options map[string]any) bool {
+		onConflict, _ := options["on_conflict"].(string)
+		typeMismatch, _ := options["type_mismatch"].(string)
+		return onConflict == OnConflictError || onConflict == OnConflictWarn || typeMismatch == TypeMismatchError
+	}
+
+	if reports(map[string]any{"on_conflict": opts.OnConflict, "type_mismatch": opts.TypeMismatch}) {
+		return true
+	}
+
+	for _, overrides := range opts.Paths {
+		if reports(overrides) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// origin returns the index of the merged object which set the value at the path, or of its closest parent.
+func (s *mergeState) origin(p pathexpr.Path) int {
+	for i := len(p); i >= 0; i-- {
+		if arg, ok := s.origins[p[:i].String()]; ok {
+			return arg
+		}
+	}
+	return 0
+}
+
+func (s *mergeState) addConflict(p pathexpr.Path, arg int, mode string) {
+	key := p.String()
+
+	c, ok := s.conflictsByPath[key]
+	if !ok {
+		c = &mergeConflict{path: key, mode: mode, args: []int{s.origin(p)}}
+		s.conflictsByPath[key] = c
+		s.conflicts = append(s.conflicts, c)
+	}
+
+	if !slices.Contains(c.args, arg) {
+		c.args = append(c.args, arg)
+	}
+}
+
+// conflictDiagnostics returns an error listing conflicts found at paths using the error mode,
+// and logs a warning for conflicts found at paths using the warn mode. Functions can't return
+// warnings to Terraform, so the warnings are only visible in logs, e.g. with TF_LOG=WARN.
+func (s *mergeState) conflictDiagnostics(ctx context.Context) (diags diag.Diagnostics) {
+	lines := map[string][]string{}
+	for _, c := range s.conflicts {
+		lines[c.mode] = append(lines[c.mode], fmt.Sprintf("  - %s: set differently by %s", c.path, formatArguments(c.args)))
+	}
+
+	for _, l := range lines {
+		sort.Strings(l)
+	}
+
+	if errors := lines[OnConflictError]; len(errors) > 0 {
+		diags.Append(diag.NewErrorDiagnostic(
+			"conflicting values in merged objects",
+			fmt.Sprintf("found %d conflicting value(s):\n%s", len(errors), strings.Join(errors, "\n")),
+		))
+	}
+
+	if warnings := lines[OnConflictWarn]; len(warnings) > 0 {
+		tflog.Warn(ctx, fmt.Sprintf("found %d conflicting value(s) in merged objects:\n%s", len(warnings), strings.Join(warnings, "\n")))
+	}
+
+	return
+}
+
+func formatArguments(args []int) string {
+	names := make([]string, len(args))
+	for i, arg := range args {
+		names[i] = fmt.Sprint(arg + 1)
+	}
+
+	if len(names) == 1 {
+		return "argument " + names[0]
+	}
+	return "arguments " + strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
+// overrides reports whether src should replace the existing dst value, recording a conflict if they differ.
+// Unknown values aren't reported as conflicts, they are compared once they are known.
+func (m merger) overrides(p pathexpr.Path, dst, src any) bool {
+	if dst == nil {
+		return true
+	}
+
+	if (m.OnConflict == OnConflictError || m.OnConflict == OnConflictWarn) && (src == nil || !equalValues(dst, src)) &&
+		!helpers.ContainsUnknown(dst) && !helpers.ContainsUnknown(src) {
+		m.state.addConflict(p, m.arg, m.OnConflict)
+	}
+
+	return m.Override && m.OnConflict != OnConflictKeepFirst
+}
+
+// set stores the value taken from the currently merged object at the key. Null values are stored too,
+// unless NullDelete removes the key. Values set again to the same value keep their origin, so conflicts
+// are attributed to the object which set the value first.
+func (m merger) set(p pathexpr.Path, dst map[string]any, key string, value any) {
+	if m.state.origins != nil {
+		if existing, ok := dst[key]; !ok || !equalValues(existing, value) {
+			m.state.origins[p.String()] = m.arg
+		}
+	}
+
+	if value == nil && m.NullDelete {
+		delete(dst, key)
+	} else {
+		m.store(dst, key, m.withoutNulls(value))
+	}
+}
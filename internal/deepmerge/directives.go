@@ -0,0 +1,287 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package deepmerge
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lablabs/terraform-provider-lara-utils/internal/pathexpr"
+)
+
+// Merge directives embedded in the merged objects, modelled after Kubernetes strategic merge patch.
+const (
+	patchDirective                   = "$patch"
+	retainKeysDirective              = "$retainKeys"
+	setElementOrderDirective         = "$setElementOrder/"
+	deleteFromPrimitiveListDirective = "$deleteFromPrimitiveList/"
+
+	patchMerge   = "merge"
+	patchReplace = "replace"
+	patchDelete  = "delete"
+)
+
+func isDirective(key string) bool {
+	return key == patchDirective ||
+		key == retainKeysDirective ||
+		strings.HasPrefix(key, setElementOrderDirective) ||
+		strings.HasPrefix(key, deleteFromPrimitiveListDirective)
+}
+
+// findDirectives reports whether the value contains any merge directives, validating their values.
+func findDirectives(v any, p pathexpr.Path) (found bool, err error) {
+	switch vv := v.(type) {
+	case map[string]any:
+		for key, elem := range vv {
+			if isDirective(key) {
+				found = true
+				if err := validateDirective(key, elem); err != nil {
+					return false, fmt.Errorf("invalid directive %q at %s: %w", key, p.Child(key), err)
+				}
+				continue
+			}
+
+			// the paths are only formatted in errors, so they can share the backing array
+			elemFound, err := findDirectives(elem, append(p, pathexpr.Segment{Key: key}))
+			if err != nil {
+				return false, err
+			}
+			found = found || elemFound
+		}
+
+	case []any:
+		for i, elem := range vv {
+			elemFound, err := findDirectives(elem, append(p, pathexpr.Segment{Index: i, IsIndex: true}))
+			if err != nil {
+				return false, err
+			}
+			found = found || elemFound
+		}
+	}
+
+	return found, nil
+}
+
+func validateDirective(key string, value any) error {
+	switch {
+	case key == patchDirective:
+		if s, ok := value.(string); !ok || (s != patchMerge && s != patchReplace && s != patchDelete) {
+			return fmt.Errorf("must be one of %q, %q or %q, got: %v", patchMerge, patchReplace, patchDelete, value)
+		}
+
+	case key == retainKeysDirective:
+		list, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("must be a list of keys, got: %T", value)
+		}
+		for _, elem := range list {
+			if _, ok := elem.(string); !ok {
+				return fmt.Errorf("must be a list of keys, got element: %v", elem)
+			}
+		}
+
+	default:
+		if _, ok := value.([]any); !ok {
+			return fmt.Errorf("must be a list, got: %T", value)
+		}
+	}
+
+	return nil
+}
+
+// stripDirectives removes merge directives from the value in place. List elements consisting only of
+// directives, e.g. { "$patch" = "replace" } list markers, are removed completely.
+func stripDirectives(v any) any {
+	switch vv := v.(type) {
+	case map[string]any:
+		for key, elem := range vv {
+			if isDirective(key) {
+				delete(vv, key)
+				continue
+			}
+			vv[key] = stripDirectives(elem)
+		}
+
+	case []any:
+		result := vv[:0]
+		for _, elem := range vv {
+			if m, ok := elem.(map[string]any); ok && len(m) > 0 && onlyDirectives(m) {
+				continue
+			}
+			result = append(result, stripDirectives(elem))
+		}
+		return result
+	}
+
+	return v
+}
+
+func onlyDirectives(m map[string]any) bool {
+	for key := range m {
+		if !isDirective(key) {
+			return false
+		}
+	}
+	return true
+}
+
+// patchDirective returns the $patch directive of an object, or an empty string if not set.
+func (m merger) patchDirective(v any) string {
+	if !m.Directives {
+		return ""
+	}
+
+	obj, _ := v.(map[string]any)
+	s, _ := obj[patchDirective].(string)
+	return s
+}
+
+// hasReplaceMarker reports whether the list contains a { "$patch" = "replace" } element, requesting
+// the whole list to be replaced.
+func (m merger) hasReplaceMarker(list []any) bool {
+	if !m.Directives {
+		return false
+	}
+
+	for _, elem := range list {
+		if obj, ok := elem.(map[string]any); ok && len(obj) == 1 && m.patchDirective(obj) == patchReplace {
+			return true
+		}
+	}
+
+	return false
+}
+
+// applyDirectives applies the $retainKeys, $setElementOrder and $deleteFromPrimitiveList directives
+// of the src object to the merged dst object.
+func (m merger) applyDirectives(p pathexpr.Path, dst, src map[string]any) {
+	if !m.Directives {
+		return
+	}
+
+	for name, elem := range src {
+		value, _ := elem.([]any)
+
+		switch {
+		case name == retainKeysDirective:
+			retain := map[string]bool{}
+			for _, k := range value {
+				retain[k.(string)] = true //nolint:forcetypeassert
+			}
+			for k := range dst {
+				if !retain[k] && !isDirective(k) {
+					delete(dst, k)
+				}
+			}
+
+		case strings.HasPrefix(name, setElementOrderDirective):
+			field := strings.TrimPrefix(name, setElementOrderDirective)
+			if list, ok := dst[field].([]any); ok {
+				dst[field] = m.at(m.child(p, field)).orderSlice(list, value)
+			}
+
+		case strings.HasPrefix(name, deleteFromPrimitiveListDirective):
+			field := strings.TrimPrefix(name, deleteFromPrimitiveListDirective)
+			if list, ok := dst[field].([]any); ok {
+				dst[field] = removeElements(list, value)
+			}
+		}
+	}
+}
+
+// applyNewDirectives applies the directives of a value stored without an existing value to merge it into,
+// like merging it into an empty value: keys set to objects with $patch: delete and list elements with
+// $patch: delete are removed, and the other directives of nested objects are applied to them.
+func (m merger) applyNewDirectives(p pathexpr.Path, v any) any {
+	if !m.directives {
+		return v
+	}
+
+	switch vv := v.(type) {
+	case map[string]any:
+		for key, elem := range vv {
+			if isDirective(key) {
+				continue
+			}
+
+			keyPath := m.child(p, key)
+			km := m.at(keyPath)
+			if km.patchDirective(elem) == patchDelete {
+				delete(vv, key)
+				continue
+			}
+			vv[key] = km.applyNewDirectives(keyPath, elem)
+		}
+		m.applyDirectives(p, vv, vv)
+
+	case []any:
+		result := vv[:0]
+		for i, elem := range vv {
+			if m.patchDirective(elem) == patchDelete {
+				continue
+			}
+			elemPath := m.element(p, i, elem)
+			result = append(result, m.at(elemPath).applyNewDirectives(elemPath, elem))
+		}
+		return result
+	}
+
+	return v
+}
+
+// orderSlice moves the list elements matching the order entries to the front, in the order given.
+// Objects are matched by MergeKey, other values by equality. Remaining elements keep their order.
+func (m merger) orderSlice(list []any, order []any) []any {
+	result := make([]any, 0, len(list))
+	used := make([]bool, len(list))
+
+	for _, entry := range order {
+		for i, elem := range list {
+			if !used[i] && m.matchesOrderEntry(elem, entry) {
+				result = append(result, elem)
+				used[i] = true
+				break
+			}
+		}
+	}
+
+	for i, elem := range list {
+		if !used[i] {
+			result = append(result, elem)
+		}
+	}
+
+	return result
+}
+
+func (m merger) matchesOrderEntry(elem any, entry any) bool {
+	if !isObject(entry) || !isObject(elem) || len(m.MergeKey) == 0 {
+		return elem != nil && equalValues(elem, entry)
+	}
+
+	key, ok := m.elementKey(elem)
+	if !ok {
+		return false
+	}
+
+	entryKey, ok := m.elementKey(entry)
+	return ok && key == entryKey
+}
+
+// removeElements returns the list without elements equal to any of the values.
+func removeElements(list []any, values []any) []any {
+	remove := make(map[string]bool, len(values))
+	for _, value := range values {
+		remove[canonicalKey(value)] = true
+	}
+
+	result := make([]any, 0, len(list))
+	for _, elem := range list {
+		if !remove[canonicalKey(elem)] {
+			result = append(result, elem)
+		}
+	}
+
+	return result
+}
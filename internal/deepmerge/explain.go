@@ -0,0 +1,89 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package deepmerge
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// Explain merges the objects like merge, returning the merged result together with the source of every
+// leaf value and the values it overrode. Sources are the indexes of the merged objects, or their labels
+// given by the Labels option. The hints are the types of the objects, or nil if they aren't known.
+//
+// The provenance is recorded by the merge itself, so the explanation always matches the result of the
+// merge with the same options.
+func Explain(ctx context.Context, objs []map[string]any, hints []attr.Type, opts DeepMergeOptions) (map[string]any, diag.Diagnostics) {
+	labels := opts.Labels
+	if len(labels) > 0 && len(labels) != len(objs) {
+		return nil, diag.Diagnostics{diag.NewErrorDiagnostic(
+			"invalid merging options",
+			fmt.Sprintf("labels must have the same length as the list of objects, got %d labels for %d objects", len(labels), len(objs)),
+		)}
+	}
+
+	pv := newProvenance()
+	merged, diags := mergeRecording(ctx, copyObjects(objs), hints, opts, pv)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	source := func(arg int) any {
+		if len(labels) > 0 {
+			return labels[arg]
+		}
+		return float64(arg)
+	}
+
+	sources := map[string]any{}
+	for path, history := range pv.leaves(merged) {
+		last := len(history) - 1
+		overridden := make([]any, last)
+		for i, entry := range history[:last] {
+			overridden[i] = map[string]any{"source": source(entry.arg), "value": entry.value}
+		}
+
+		sources[path] = map[string]any{
+			"source":     source(history[last].arg),
+			"overridden": overridden,
+		}
+	}
+
+	return map[string]any{
+		"result":  merged,
+		"sources": sources,
+	}, nil
+}
+
+// copyObjects deeply copies the objects, as merging modifies nested values of the merged objects in place.
+func copyObjects(objs []map[string]any) []map[string]any {
+	result := make([]map[string]any, len(objs))
+	for i, obj := range objs {
+		result[i] = deepCopy(obj).(map[string]any) //nolint:forcetypeassert
+	}
+	return result
+}
+
+func deepCopy(v any) any {
+	switch vv := v.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(vv))
+		for k, elem := range vv {
+			result[k] = deepCopy(elem)
+		}
+		return result
+
+	case []any:
+		result := make([]any, len(vv))
+		for i, elem := range vv {
+			result[i] = deepCopy(elem)
+		}
+		return result
+	}
+
+	return v
+}
@@ -0,0 +1,137 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package deepmerge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	// source returns the explanation of a value set by the object with the index, overriding the values.
+	source := func(arg float64, overridden ...any) map[string]any {
+		if overridden == nil {
+			overridden = []any{}
+		}
+		return map[string]any{"source": arg, "overridden": overridden}
+	}
+	value := func(arg float64, v any) map[string]any {
+		return map[string]any{"source": arg, "value": v}
+	}
+
+	tests := []struct {
+		name     string
+		options  map[string]any
+		objs     []map[string]any
+		expected map[string]any
+	}{
+		{
+			name: "overridden values",
+			objs: []map[string]any{
+				{"replicas": "1", "image": map[string]any{"repository": "app", "tag": "1.0"}, "tags": []any{"base"}},
+				{"replicas": "3", "tags": []any{"production"}},
+				{"image": map[string]any{"tag": "1.1"}},
+			},
+			expected: map[string]any{
+				"replicas":         source(1, value(0, "1")),
+				"image.repository": source(0),
+				"image.tag":        source(2, value(0, "1.0")),
+				"tags[0]":          source(1, value(0, "base")),
+			},
+		},
+		{
+			name: "values set again keep their source",
+			objs: []map[string]any{{"a": "x"}, {"a": "x"}, {"a": "y"}, {"a": "y"}},
+			expected: map[string]any{
+				"a": source(2, value(0, "x")),
+			},
+		},
+		{
+			name:    "prepended lists",
+			options: map[string]any{"prepend_list": true},
+			objs: []map[string]any{
+				{"args": []any{"--a", "--b"}},
+				{"args": []any{"--c"}},
+				{"args": []any{"--d"}},
+			},
+			expected: map[string]any{
+				"args[0]": source(2),
+				"args[1]": source(1),
+				"args[2]": source(0),
+				"args[3]": source(0),
+			},
+		},
+		{
+			name:    "elements deleted by merge key",
+			options: map[string]any{"merge_key": "name"},
+			objs: []map[string]any{
+				{"env": []any{map[string]any{"name": "A", "value": "1"}, map[string]any{"name": "B", "value": "2"}}},
+				{"env": []any{map[string]any{"name": "A", "$patch": "delete"}}},
+				{"env": []any{map[string]any{"name": "B", "value": "3"}, map[string]any{"name": "C", "value": "4"}}},
+			},
+			expected: map[string]any{
+				"env[0].name":  source(0),
+				"env[0].value": source(2, value(0, "2")),
+				"env[1].name":  source(2),
+				"env[1].value": source(2),
+			},
+		},
+		{
+			name:    "atomic values",
+			options: map[string]any{"atomic_paths": "image"},
+			objs: []map[string]any{
+				{"image": map[string]any{"repository": "app", "tag": "1.0"}},
+				{"image": map[string]any{"tag": "1.1"}},
+			},
+			expected: map[string]any{
+				"image.tag": source(1, value(0, "1.0")),
+			},
+		},
+		{
+			name:    "merged by index",
+			options: map[string]any{"merge_by_index": true},
+			objs: []map[string]any{
+				{"ports": []any{"80", "443"}},
+				{"ports": []any{"8080", nil, "9090"}},
+			},
+			expected: map[string]any{
+				"ports[0]": source(1, value(0, "80")),
+				"ports[1]": source(0),
+				"ports[2]": source(1),
+			},
+		},
+		{
+			name:    "sorted lists",
+			options: map[string]any{"union_lists": true, "sort_lists": true},
+			objs: []map[string]any{
+				{"hosts": []any{"b", "c"}},
+				{"hosts": []any{"a", "c"}},
+			},
+			expected: map[string]any{
+				"hosts[0]": source(1),
+				"hosts[1]": source(0),
+				"hosts[2]": source(0),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := NewDefaultOptions()
+			if tt.options != nil {
+				assert.NoError(t, DecodeOptions(tt.options, opts))
+			}
+
+			explained, diags := Explain(context.Background(), tt.objs, nil, *opts)
+			assert.False(t, diags.HasError(), diags)
+
+			merged, diags := merge(context.Background(), copyObjects(tt.objs), nil, *opts)
+			assert.False(t, diags.HasError(), diags)
+			assert.Equal(t, merged, explained["result"])
+			assert.Equal(t, tt.expected, explained["sources"])
+		})
+	}
+}
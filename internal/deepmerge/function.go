@@ -5,28 +5,83 @@ package deepmerge
 
 import (
 	"context"
+	"fmt"
+	"maps"
+	"math/big"
+	"reflect"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/lablabs/terraform-provider-lara-utils/internal/helpers"
+	"github.com/lablabs/terraform-provider-lara-utils/internal/pathexpr"
 )
 
 type DeepMergeFunction interface {
 	FunctionSummary() string
 	FunctionDescription() string
 	FunctionObjectsParameter() function.Parameter
-	FunctionResult(context.Context, map[string]any) (basetypes.DynamicValue, diag.Diagnostics)
+	FunctionResult(context.Context, map[string]any, []attr.Type) (basetypes.DynamicValue, diag.Diagnostics)
 
+	// GetMergingObjects returns the objects to merge, or nil if the objects or any of them are unknown,
+	// making the whole result unknown.
 	GetMergingObjects(context.Context, function.ArgumentsData) ([]map[string]any, *function.FuncError)
+	GetMergingTypes(context.Context, function.ArgumentsData) ([]attr.Type, *function.FuncError)
 	GetMergingOptions(context.Context, function.ArgumentsData) (*DeepMergeOptions, *function.FuncError)
 }
 
 type DeepMergeOptions struct {
-	Override     bool `mapstructure:"override"`
-	NullOverride bool `mapstructure:"null_override"`
-	AppendList   bool `mapstructure:"append_list"`
-	DeepCopyList bool `mapstructure:"deep_copy_list"`
-	UnionLists   bool `mapstructure:"union_lists"`
+	Override     bool     `mapstructure:"override"`
+	NullOverride bool     `mapstructure:"null_override"`
+	NullDelete   bool     `mapstructure:"null_delete"`
+	AppendList   bool     `mapstructure:"append_list"`
+	PrependList  bool     `mapstructure:"prepend_list"`
+	DeepCopyList bool     `mapstructure:"deep_copy_list"`
+	MergeByIndex bool     `mapstructure:"merge_by_index"`
+	UnionLists   bool     `mapstructure:"union_lists"`
+	PrependUnion bool     `mapstructure:"prepend_union"`
+	MergeKey     []string `mapstructure:"merge_key"`
+	Directives   bool     `mapstructure:"directives"`
+	OnConflict   string   `mapstructure:"on_conflict"`
+	TypeMismatch string   `mapstructure:"type_mismatch"`
+
+	// NullDeleteElements removes null list elements when NullDelete is set.
+	NullDeleteElements bool `mapstructure:"null_delete_elements"`
+
+	// MaxDepth stops merging objects below the depth, replacing deeper values as a whole. Zero means no limit.
+	MaxDepth int `mapstructure:"max_depth"`
+	// AtomicPaths are path patterns of values which are replaced as a whole instead of being merged.
+	AtomicPaths []string `mapstructure:"atomic_paths"`
+	// UnionBy lists the paths of fields identifying objects in lists merged as sets.
+	UnionBy []string `mapstructure:"union_by"`
+	// UnionByStrategy controls whether objects with the same identity replace or are merged into earlier ones.
+	UnionByStrategy string `mapstructure:"union_by_strategy"`
+	// SortLists sorts merged lists, ordering objects by the SortBy fields.
+	SortLists bool     `mapstructure:"sort_lists"`
+	SortBy    []string `mapstructure:"sort_by"`
+
+	// Paths overrides options for subtrees matching the path patterns.
+	Paths map[string]map[string]any `mapstructure:"paths"`
+
+	// Profile names the preset of options applied before other options of the same options object.
+	Profile string `mapstructure:"profile"`
+
+	// Yaml controls the layout of the result of yaml_deep_merge, the only function accepting it.
+	Yaml YamlOptions `mapstructure:"yaml"`
+
+	// Labels name the merged objects in the explanation of deep_merge_explain, the only function accepting it.
+	Labels []string `mapstructure:"labels"`
+}
+
+// YamlOptions are the settings of the yaml option.
+type YamlOptions struct {
+	helpers.YAMLFormat `mapstructure:",squash"`
+	// PreserveSource lays out the result like the merged documents, keeping their comments, key order
+	// and scalar styles.
+	PreserveSource bool `mapstructure:"preserve_source"`
 }
 
 func NewFunctionDefinition(fn DeepMergeFunction) function.Definition {
@@ -48,12 +103,152 @@ func NewFunctionDefinition(fn DeepMergeFunction) function.Definition {
 
 func NewDefaultOptions() *DeepMergeOptions {
 	return &DeepMergeOptions{
-		Override:     true,
-		NullOverride: true,
-		AppendList:   false,
-		DeepCopyList: false,
-		UnionLists:   false,
+		Override:        true,
+		NullOverride:    true,
+		AppendList:      false,
+		PrependList:     false,
+		DeepCopyList:    false,
+		MergeByIndex:    false,
+		UnionLists:      false,
+		PrependUnion:    false,
+		MergeKey:        []string{},
+		Directives:      true,
+		OnConflict:      OnConflictOverride,
+		TypeMismatch:    TypeMismatchReplace,
+		AtomicPaths:     []string{},
+		UnionBy:         []string{},
+		UnionByStrategy: UnionByReplace,
+		SortLists:       false,
+		SortBy:          []string{},
+		Paths:           map[string]map[string]any{},
+		Labels:          []string{},
+	}
+}
+
+// DecodeOptions decodes a single options object into opts, keeping values not present in input.
+// Unknown options and values of wrong types are rejected according to OptionsSchema, as are options
+// accepted only by some functions, which are decoded by DecodeFunctionOptions.
+func DecodeOptions(input any, opts *DeepMergeOptions) error {
+	return DecodeFunctionOptions("", input, opts)
+}
+
+// DecodeFunctionOptions decodes a single options object like DecodeOptions, additionally accepting
+// the options of the named merge function.
+func DecodeFunctionOptions(function string, input any, opts *DeepMergeOptions) error {
+	if err := checkOptions(input, function); err != nil {
+		return err
+	}
+
+	if profile, ok := input.(map[string]any)["profile"].(string); ok { //nolint:forcetypeassert
+		if err := opts.applyProfile(profile); err != nil {
+			return err
+		}
+	}
+
+	if err := decodeOptions(input, opts); err != nil {
+		return err
+	}
+
+	return opts.validate()
+}
+
+// validate checks option values which can't be checked by the schema.
+func (opts DeepMergeOptions) validate() error {
+	for _, field := range opts.UnionBy {
+		if _, err := pathexpr.ParsePath(field); err != nil {
+			return fmt.Errorf("invalid union_by field: %w", err)
+		}
+	}
+
+	for _, field := range opts.SortBy {
+		if _, err := pathexpr.ParsePath(field); err != nil {
+			return fmt.Errorf("invalid sort_by field: %w", err)
+		}
+	}
+
+	if opts.MaxDepth < 0 {
+		return fmt.Errorf("max_depth must not be negative, got: %d", opts.MaxDepth)
+	}
+
+	if opts.Yaml.Indent != 0 && (opts.Yaml.Indent < 2 || opts.Yaml.Indent > 9) {
+		return fmt.Errorf("yaml.indent must be between 2 and 9, got: %d", opts.Yaml.Indent)
+	}
+	if opts.Yaml.LineWidth < 0 {
+		return fmt.Errorf("yaml.line_width must not be negative, got: %d", opts.Yaml.LineWidth)
 	}
+	if opts.Yaml.FlowLists < 0 {
+		return fmt.Errorf("yaml.flow_lists must not be negative, got: %d", opts.Yaml.FlowLists)
+	}
+
+	if _, err := opts.compileAtomicPaths(); err != nil {
+		return err
+	}
+
+	_, err := opts.compilePaths()
+	return err
+}
+
+// decodeOptions decodes the options object into opts. Paths are added to the paths already set,
+// other options replace the values already set, unless they are null.
+func decodeOptions(input any, opts *DeepMergeOptions) error {
+	if obj, ok := input.(map[string]any); ok {
+		input = withoutNullOptions(obj)
+	}
+
+	paths := opts.Paths
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:  mapstructure.ComposeDecodeHookFunc(numberToFloatHook, mapstructure.StringToSliceHookFunc(",")),
+		ErrorUnused: true,
+		ZeroFields:  true,
+		Result:      opts,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := decoder.Decode(input); err != nil {
+		return err
+	}
+
+	if len(paths) > 0 {
+		merged := maps.Clone(paths)
+		maps.Copy(merged, opts.Paths)
+		opts.Paths = merged
+	}
+
+	return nil
+}
+
+// withoutNullOptions returns the options object without null values, which keep the default values
+// or the values set by earlier options objects and profiles. Null settings of object options are
+// removed too.
+func withoutNullOptions(obj map[string]any) map[string]any {
+	result := make(map[string]any, len(obj))
+	for key, value := range obj {
+		if nested, ok := value.(map[string]any); ok && isObjectOption(key) {
+			value = withoutNullOptions(nested)
+		}
+		if value != nil {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// numberToFloatHook converts numbers to float64, which mapstructure can decode into numeric options.
+// Numbers decoded into integer options are converted to int64 instead, exactly, as their range is
+// checked by the schema.
+func numberToFloatHook(_ reflect.Type, to reflect.Type, data any) (any, error) {
+	if n, ok := data.(*big.Float); ok {
+		if to.Kind() == reflect.Int {
+			i, _ := n.Int64()
+			return i, nil
+		}
+		f, _ := n.Float64()
+		return f, nil
+	}
+	return data, nil
 }
 
 func Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse, fn DeepMergeFunction) {
@@ -61,19 +256,34 @@ func Run(ctx context.Context, req function.RunRequest, resp *function.RunRespons
 	if resp.Error = function.ConcatFuncErrors(err); resp.Error != nil {
 		return
 	}
+	if objs == nil {
+		resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, basetypes.NewDynamicUnknown()))
+		return
+	}
 
 	opts, err := fn.GetMergingOptions(ctx, req.Arguments)
 	if resp.Error = function.ConcatFuncErrors(err); resp.Error != nil {
 		return
 	}
 
-	merged, diags := merge(objs, *opts)
+	hints, err := fn.GetMergingTypes(ctx, req.Arguments)
+	if resp.Error = function.ConcatFuncErrors(err); resp.Error != nil {
+		return
+	}
+
+	RunMerge(ctx, resp, fn, objs, hints, *opts)
+}
+
+// RunMerge merges the objects and sets the result like Run, for functions decoding their arguments
+// themselves.
+func RunMerge(ctx context.Context, resp *function.RunResponse, fn DeepMergeFunction, objs []map[string]any, hints []attr.Type, opts DeepMergeOptions) {
+	merged, diags := merge(ctx, objs, hints, opts)
 	if diags.HasError() {
 		resp.Error = function.FuncErrorFromDiags(ctx, diags)
 		return
 	}
 
-	result, diags := fn.FunctionResult(ctx, merged)
+	result, diags := fn.FunctionResult(ctx, merged, hints)
 	if diags.HasError() {
 		resp.Error = function.FuncErrorFromDiags(ctx, diags)
 		return
@@ -0,0 +1,496 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+// The merging options started as the mergo based options of
+// https://github.com/isometry/terraform-provider-deepmerge/blob/main/internal/provider/mergo_function.go,
+// this native merge engine replaced mergo while keeping their behavior.
+
+package deepmerge
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+
+	"github.com/lablabs/terraform-provider-lara-utils/internal/helpers"
+	"github.com/lablabs/terraform-provider-lara-utils/internal/pathexpr"
+)
+
+// Values of the union_by_strategy option, controlling how objects with the same identity are combined.
+const (
+	UnionByReplace = "replace"
+	UnionByMerge   = "merge"
+)
+
+// merger merges decoded values, i.e. objects, lists, strings, numbers, bools and nulls, recursively.
+type merger struct {
+	DeepMergeOptions
+	paths  []pathOptions
+	atomic []pathexpr.Expression
+	// hints are the types of the merged objects, if known, used to merge sets as unions.
+	hints []attr.Type
+	state *mergeState
+	// arg is the index of the currently merged object.
+	arg int
+	// trackPaths enables building the paths of the merged values, needed only by some options.
+	trackPaths bool
+	// directives reports whether any of the merged objects contains merge directives.
+	directives bool
+}
+
+// merge merges the objects in order. The hints are the types of the objects, or nil if they aren't known.
+func merge(ctx context.Context, objs []map[string]any, hints []attr.Type, opts DeepMergeOptions) (map[string]any, diag.Diagnostics) {
+	return mergeRecording(ctx, objs, hints, opts, nil)
+}
+
+// mergeRecording merges the objects like merge, recording the histories of the merged values into pv if not nil.
+func mergeRecording(ctx context.Context, objs []map[string]any, hints []attr.Type, opts DeepMergeOptions, pv *provenance) (merged map[string]any, diags diag.Diagnostics) {
+	paths, err := opts.compilePaths()
+	if err != nil {
+		diags.Append(diag.NewErrorDiagnostic("invalid merging options", err.Error()))
+		return
+	}
+
+	atomic, err := opts.compileAtomicPaths()
+	if err != nil {
+		diags.Append(diag.NewErrorDiagnostic("invalid merging options", err.Error()))
+		return
+	}
+
+	directives := false
+	if opts.Directives {
+		for i, m := range objs {
+			found, err := findDirectives(m, pathexpr.Path{})
+			if err != nil {
+				diags.Append(diag.NewErrorDiagnostic(fmt.Sprintf("error merging argument %d", i+1), err.Error()))
+				return
+			}
+			directives = directives || found
+		}
+	}
+
+	m := merger{
+		DeepMergeOptions: opts,
+		paths:            paths,
+		atomic:           atomic,
+		hints:            hints,
+		state:            newMergeState(opts.tracksOrigins()),
+		trackPaths:       opts.tracksOrigins() || len(paths) > 0 || len(atomic) > 0 || opts.MaxDepth > 0 || slices.ContainsFunc(hints, hasSets),
+		directives:       directives,
+	}
+
+	m.state.provenance = pv
+
+	dst := make(map[string]any)
+	for i, obj := range objs {
+		m.arg = i
+
+		switch m.patchDirective(obj) {
+		case patchDelete:
+			clear(dst)
+			continue
+		case patchReplace:
+			clear(dst)
+		}
+		dst = m.mergeObjects(pathexpr.Path{}, dst, obj)
+	}
+
+	diags.Append(m.state.mismatchDiagnostics()...)
+	diags.Append(m.state.conflictDiagnostics(ctx)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	if directives {
+		stripDirectives(dst)
+	}
+
+	if opts.sortsLists() {
+		m.sortLists(dst, pathexpr.Path{})
+	}
+
+	return dst, nil
+}
+
+// child returns the path of the object key, or nil if paths aren't tracked.
+func (m merger) child(p pathexpr.Path, key string) pathexpr.Path {
+	if !m.trackPaths {
+		return nil
+	}
+	return p.Child(key)
+}
+
+// element returns the path of the list element, or nil if paths aren't tracked.
+func (m merger) element(p pathexpr.Path, i int, elem any) pathexpr.Path {
+	if !m.trackPaths {
+		return nil
+	}
+	return p.Element(i, elem)
+}
+
+// at returns the merger with options overridden by all path patterns matching p.
+func (m merger) at(p pathexpr.Path) merger {
+	if len(m.paths) == 0 {
+		return m
+	}
+	return m.withPathOptions(p)
+}
+
+func (m merger) withPathOptions(p pathexpr.Path) merger {
+	for _, po := range m.paths {
+		if po.pattern.Match(p) {
+			po.apply(&m.DeepMergeOptions)
+		}
+	}
+	return m
+}
+
+// mergeObjects merges the src object into the dst object in place, returning dst.
+func (m merger) mergeObjects(p pathexpr.Path, dst, src map[string]any) map[string]any {
+	for key, srcElem := range src {
+		keyPath := m.child(p, key)
+		km := m.at(keyPath)
+
+		// missing keys and null values are treated the same, empty values are kept like any other
+		dstElem := dst[key]
+
+		srcList, srcIsList := srcElem.([]any)
+		dstList, dstIsList := dstElem.([]any)
+		lists := srcIsList && dstIsList
+
+		switch {
+		case km.Directives && isDirective(key): // directives are applied after merging other keys
+			continue
+		case km.patchDirective(srcElem) == patchDelete: // handle $patch: delete
+			delete(dst, key)
+		case km.patchDirective(srcElem) == patchReplace: // handle $patch: replace
+			km.set(keyPath, dst, key, km.applyNewDirectives(keyPath, srcElem))
+		case km.mismatches(keyPath, dstElem, srcElem) && km.TypeMismatch != TypeMismatchReplace: // keep values of different types
+			continue
+		case srcElem != nil && km.isAtomic(keyPath) && !km.overrides(keyPath, dstElem, srcElem): // keep atomic values without override
+			continue
+		case srcElem != nil && km.isAtomic(keyPath): // replace atomic values as a whole
+			km.set(keyPath, dst, key, srcElem)
+		case km.mergesUnknown(keyPath, dstElem, srcElem): // values merged with unknown values are unknown
+			km.set(keyPath, dst, key, helpers.NewUnknown())
+		case isObject(srcElem) && isObject(dstElem):
+			dst[key] = km.mergeObjects(keyPath, dstElem.(map[string]any), srcElem.(map[string]any)) //nolint:forcetypeassert
+		case srcElem == nil && km.NullDelete: // delete keys set to nil values
+			delete(dst, key)
+		case srcElem == nil && !km.NullOverride: // skip override of nil values only if nullOverride is false
+			continue
+		case srcIsList && km.hasReplaceMarker(srcList): // handle list $patch: replace
+			km.set(keyPath, dst, key, srcElem)
+		case lists && km.mergesUnknownElements(keyPath, dstList, srcList): // lists merged depending on unknown elements are unknown
+			km.set(keyPath, dst, key, helpers.NewUnknown())
+		case lists && km.canMergeByKey(dstList, srcList): // handle merge by key
+			km.store(dst, key, km.mergeSlicesByKey(keyPath, dstList, srcList))
+		case lists && len(km.UnionBy) > 0: // handle union by identity
+			km.set(keyPath, dst, key, km.unionSlicesBy(keyPath, dstList, srcList))
+		case lists && km.isSet(keyPath): // handle sets
+			km.set(keyPath, dst, key, unionSlices(dstList, srcList))
+		case lists && km.PrependUnion: // handle prepend union
+			km.set(keyPath, dst, key, unionSlices(srcList, dstList))
+		case lists && km.UnionLists: // handle union
+			km.set(keyPath, dst, key, unionSlices(dstList, srcList))
+		case lists && km.PrependList: // handle prepend
+			km.set(keyPath, dst, key, concatSlices(srcList, dstList))
+		case lists && km.AppendList: // handle append
+			km.set(keyPath, dst, key, concatSlices(dstList, srcList))
+		case lists && km.MergeByIndex: // handle merge by index
+			km.set(keyPath, dst, key, km.mergeSlicesByIndex(keyPath, dstList, srcList))
+		case lists && km.DeepCopyList: // handle deep copy
+			km.store(dst, key, km.deepCopySlices(keyPath, dstList, srcList))
+		case !km.overrides(keyPath, dstElem, srcElem): // keep existing values without override
+			continue
+		default:
+			km.set(keyPath, dst, key, km.applyNewDirectives(keyPath, srcElem))
+		}
+	}
+
+	m.applyDirectives(p, dst, src)
+
+	return dst
+}
+
+func isObject(v any) bool {
+	_, ok := v.(map[string]any)
+	return ok
+}
+
+// isAtomic reports whether the value at the path is replaced as a whole, because it is deeper than MaxDepth
+// or matches any of the AtomicPaths patterns.
+func (m merger) isAtomic(p pathexpr.Path) bool {
+	if m.MaxDepth > 0 && p.Depth() >= m.MaxDepth {
+		return true
+	}
+
+	for _, pattern := range m.atomic {
+		if pattern.Match(p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// canMergeByKey reports whether both slices can be merged by MergeKey, which
+// requires every element of both slices to be an object.
+func (m merger) canMergeByKey(dst, src []any) bool {
+	if len(m.MergeKey) == 0 {
+		return false
+	}
+
+	for _, slice := range [][]any{dst, src} {
+		for _, elem := range slice {
+			if !isObject(elem) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// mergeSlicesByKey merges src elements into dst elements having the same MergeKey
+// values, appending src elements without a matching dst element.
+func (m merger) mergeSlicesByKey(p pathexpr.Path, dst, src []any) []any {
+	result := make([]any, 0, len(dst)+len(src))
+	result = append(result, dst...)
+
+	// positions of the result elements by their keys, removed elements are set to nil
+	positions := map[string][]int{}
+	for i, elem := range result {
+		if key, ok := m.elementKey(elem); ok {
+			positions[key] = append(positions[key], i)
+		}
+	}
+	removed := 0
+
+	for _, srcElem := range src {
+		patch := m.patchDirective(srcElem)
+
+		key, ok := m.elementKey(srcElem)
+		if !ok || len(positions[key]) == 0 {
+			if patch != patchDelete {
+				if ok {
+					positions[key] = append(positions[key], len(result))
+				}
+				result = append(result, srcElem)
+			}
+			continue
+		}
+
+		j := positions[key][0]
+		elemPath := m.element(p, liveIndex(result, j, removed), result[j])
+		et := m.at(elemPath)
+
+		if patch != patchDelete && et.isAtomic(elemPath) {
+			patch = patchReplace
+		}
+
+		switch patch {
+		case patchDelete:
+			result[j] = nil
+			positions[key] = positions[key][1:]
+			removed++
+		case patchReplace:
+			result[j] = srcElem
+		default:
+			result[j] = et.mergeObjects(elemPath, result[j].(map[string]any), srcElem.(map[string]any)) //nolint:forcetypeassert
+		}
+	}
+
+	if removed > 0 {
+		compacted := result[:0]
+		for _, elem := range result {
+			if elem != nil {
+				compacted = append(compacted, elem)
+			}
+		}
+		result = compacted
+	}
+
+	return result
+}
+
+// liveIndex returns the index of the j-th element of a slice with removed elements set to nil, as it will be
+// after the removed elements are compacted.
+func liveIndex(slice []any, j, removed int) int {
+	if removed == 0 {
+		return j
+	}
+
+	index := j
+	for _, elem := range slice[:j] {
+		if elem == nil {
+			index--
+		}
+	}
+	return index
+}
+
+// elementKey returns the canonical key of the MergeKey values of an object element, or false if any of them is missing.
+func (m merger) elementKey(elem any) (string, bool) {
+	obj, ok := elem.(map[string]any)
+	if !ok {
+		return "", false
+	}
+
+	key := make([]any, len(m.MergeKey))
+	for i, field := range m.MergeKey {
+		if key[i] = obj[field]; key[i] == nil {
+			return "", false
+		}
+	}
+	return canonicalKey(key), true
+}
+
+// deepCopySlices merges objects at the same positions of both slices, keeping the other dst elements.
+// Values of the merged objects are always overridden, as documented for deep_copy_list.
+func (m merger) deepCopySlices(p pathexpr.Path, dst, src []any) []any {
+	result := make([]any, len(dst))
+	copy(result, dst)
+
+	for i := 0; i < len(src) && i < len(dst); i++ {
+		srcObj, srcOk := src[i].(map[string]any)
+		dstObj, dstOk := result[i].(map[string]any)
+		elemPath := m.element(p, i, result[i])
+
+		et := m.at(elemPath)
+		et.Override = true
+		switch {
+		case et.isAtomic(elemPath):
+		case srcOk && dstOk:
+			result[i] = et.mergeObjects(elemPath, dstObj, srcObj)
+		case mergesUnknownObject(result[i], src[i]): // objects merged with unknown elements are unknown
+			result[i] = helpers.NewUnknown()
+		}
+	}
+
+	return result
+}
+
+// mergeSlicesByIndex merges elements at the same positions of both slices. Objects are merged recursively,
+// null src elements keep the dst element, and src elements beyond the end of dst are appended.
+func (m merger) mergeSlicesByIndex(p pathexpr.Path, dst, src []any) []any {
+	result := make([]any, len(dst), max(len(dst), len(src)))
+	copy(result, dst)
+
+	for i, srcElem := range src {
+		if i >= len(dst) {
+			result = append(result, srcElem)
+			continue
+		}
+
+		dstElem := result[i]
+		elemPath := m.element(p, i, dstElem)
+		et := m.at(elemPath)
+
+		switch {
+		case srcElem == nil: // null placeholders keep the dst element
+		case et.mismatches(elemPath, dstElem, srcElem) && et.TypeMismatch != TypeMismatchReplace: // keep elements of different types
+		case mergesUnknownObject(dstElem, srcElem) && !et.isAtomic(elemPath): // objects merged with unknown elements are unknown
+			result[i] = helpers.NewUnknown()
+		case isObject(srcElem) && isObject(dstElem) && !et.isAtomic(elemPath):
+			result[i] = et.mergeObjects(elemPath, dstElem.(map[string]any), srcElem.(map[string]any)) //nolint:forcetypeassert
+		case et.overrides(elemPath, dstElem, srcElem):
+			result[i] = srcElem
+		}
+	}
+
+	return result
+}
+
+// unionSlicesBy merges the slices as sets of elements identified by the UnionBy fields. Elements having
+// the same identity as an earlier element replace it or are merged into it, depending on UnionByStrategy.
+// Elements without all identity fields are compared by equality.
+func (m merger) unionSlicesBy(p pathexpr.Path, dst, src []any) []any {
+	fields := make([]pathexpr.Path, len(m.UnionBy))
+	for i, field := range m.UnionBy {
+		fields[i], _ = pathexpr.ParsePath(field) // validated by DecodeOptions
+	}
+
+	result := make([]any, 0, len(dst)+len(src))
+	identities := map[string]int{}
+	seen := map[string]bool{}
+
+	for _, slice := range [][]any{dst, src} {
+		for _, elem := range slice {
+			identity := elementIdentity(elem, fields)
+			if identity == nil {
+				if key := canonicalKey(elem); !seen[key] {
+					seen[key] = true
+					result = append(result, elem)
+				}
+				continue
+			}
+
+			key := canonicalKey(identity)
+			j, ok := identities[key]
+			if !ok {
+				identities[key] = len(result)
+				result = append(result, elem)
+				continue
+			}
+
+			existing := result[j]
+			elemPath := m.element(p, j, existing)
+			et := m.at(elemPath)
+			switch {
+			case et.UnionByStrategy == UnionByMerge:
+				result[j] = et.mergeObjects(elemPath, existing.(map[string]any), elem.(map[string]any)) //nolint:forcetypeassert
+			case et.overrides(elemPath, existing, elem):
+				result[j] = elem
+			}
+		}
+	}
+
+	return result
+}
+
+// elementIdentity returns the values of the identity fields of an object element, or nil if any of them is missing.
+func elementIdentity(elem any, fields []pathexpr.Path) []any {
+	if _, ok := elem.(map[string]any); !ok {
+		return nil
+	}
+
+	identity := make([]any, len(fields))
+	for i, field := range fields {
+		value, ok := field.Lookup(elem)
+		if !ok || value == nil {
+			return nil
+		}
+		identity[i] = value
+	}
+
+	return identity
+}
+
+// concatSlices returns a new slice with elements of a followed by elements of b.
+func concatSlices(a, b []any) []any {
+	result := make([]any, 0, len(a)+len(b))
+	result = append(result, a...)
+	return append(result, b...)
+}
+
+// unionSlices returns a new slice with distinct elements of dst followed by distinct elements of src
+// missing in dst, keeping their order.
+func unionSlices(dst, src []any) []any {
+	result := make([]any, 0, len(dst)+len(src))
+	seen := make(map[string]bool, len(dst)+len(src))
+
+	for _, slice := range [][]any{dst, src} {
+		for _, elem := range slice {
+			if key := canonicalKey(elem); !seen[key] {
+				seen[key] = true
+				result = append(result, elem)
+			}
+		}
+	}
+
+	return result
+}
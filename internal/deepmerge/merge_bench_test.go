@@ -0,0 +1,87 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package deepmerge
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// benchmarkValues returns Helm-like values with nested objects and lists of size items. The variant
+// shifts the values, so values of different variants partially overlap.
+func benchmarkValues(size, variant int) map[string]any {
+	env := make([]any, size)
+	hosts := make([]any, size)
+	for i := range size {
+		env[i] = map[string]any{
+			"name":  fmt.Sprintf("VAR_%d", i+variant*size/2),
+			"value": fmt.Sprintf("value-%d-%d", i, variant),
+		}
+		hosts[i] = fmt.Sprintf("host-%d.example.com", i+variant*size/2)
+	}
+
+	services := make(map[string]any, size/10)
+	for i := range size / 10 {
+		services[fmt.Sprintf("service-%d", i)] = map[string]any{
+			"enabled":  i%2 == variant%2,
+			"replicas": float64(i + variant),
+			"resources": map[string]any{
+				"limits":   map[string]any{"cpu": "500m", "memory": fmt.Sprintf("%dMi", 128*(variant+1))},
+				"requests": map[string]any{"cpu": "100m", "memory": "64Mi"},
+			},
+			"labels": []any{"app", fmt.Sprintf("tier-%d", variant)},
+		}
+	}
+
+	return map[string]any{
+		"global": map[string]any{
+			"image": map[string]any{"repository": "nginx", "tag": fmt.Sprintf("1.%d", variant)},
+			"env":   env,
+			"hosts": hosts,
+		},
+		"services": services,
+	}
+}
+
+func benchmarkMerge(b *testing.B, size int, opts DeepMergeOptions) {
+	b.Helper()
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		// merging modifies the objects in place, so every iteration needs fresh ones
+		b.StopTimer()
+		objs := []map[string]any{benchmarkValues(size, 0), benchmarkValues(size, 1), benchmarkValues(size, 2)}
+		b.StartTimer()
+
+		if _, diags := merge(context.Background(), objs, nil, opts); diags.HasError() {
+			b.Fatal(diags)
+		}
+	}
+}
+
+func BenchmarkMerge(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		opts func(*DeepMergeOptions)
+	}{
+		{"override", func(*DeepMergeOptions) {}},
+		{"append_list", func(opts *DeepMergeOptions) { opts.AppendList = true }},
+		{"union_lists", func(opts *DeepMergeOptions) { opts.UnionLists = true }},
+		{"merge_key", func(opts *DeepMergeOptions) { opts.MergeKey = []string{"name"} }},
+		{"union_by", func(opts *DeepMergeOptions) { opts.UnionBy = []string{"name"} }},
+		{"on_conflict_warn", func(opts *DeepMergeOptions) { opts.OnConflict = OnConflictWarn }},
+	}
+
+	for _, bm := range benchmarks {
+		for _, size := range []int{100, 1000, 5000} {
+			b.Run(fmt.Sprintf("%s/%d", bm.name, size), func(b *testing.B) {
+				opts := *NewDefaultOptions()
+				bm.opts(&opts)
+				benchmarkMerge(b, size, opts)
+			})
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package deepmerge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeNulls(t *testing.T) {
+	objs := []map[string]any{
+		{"a": "x", "b": nil, "list": []any{"x"}},
+		{"a": nil, "c": nil},
+	}
+
+	stored := map[string]any{"a": nil, "b": nil, "c": nil, "list": []any{"x"}}
+
+	tests := []struct {
+		name     string
+		options  map[string]any
+		expected map[string]any
+	}{
+		{name: "default", options: map[string]any{}, expected: stored},
+		{name: "sort_lists", options: map[string]any{"sort_lists": true}, expected: stored},
+		{name: "union_lists", options: map[string]any{"union_lists": true}, expected: stored},
+		{name: "merge_key", options: map[string]any{"merge_key": "name"}, expected: stored},
+		{name: "on_conflict warn", options: map[string]any{"on_conflict": "warn"}, expected: stored},
+		{name: "unrelated paths", options: map[string]any{"paths": map[string]any{"list": map[string]any{"append_list": true}}}, expected: stored},
+		{
+			name:     "null_delete",
+			options:  map[string]any{"null_delete": true},
+			expected: map[string]any{"list": []any{"x"}},
+		},
+		{
+			name:     "null_delete by path",
+			options:  map[string]any{"paths": map[string]any{"a": map[string]any{"null_delete": true}}},
+			expected: map[string]any{"b": nil, "c": nil, "list": []any{"x"}},
+		},
+		{
+			name:     "null_override disabled",
+			options:  map[string]any{"null_override": false},
+			expected: map[string]any{"a": "x", "list": []any{"x"}},
+		},
+		{
+			name:     "override disabled",
+			options:  map[string]any{"override": false},
+			expected: map[string]any{"a": "x", "b": nil, "c": nil, "list": []any{"x"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := NewDefaultOptions()
+			assert.NoError(t, DecodeOptions(tt.options, opts))
+
+			merged, diags := merge(context.Background(), copyObjects(objs), nil, *opts)
+			assert.False(t, diags.HasError(), diags)
+			assert.Equal(t, tt.expected, merged)
+		})
+	}
+}
+
+func TestMergeEmptyValues(t *testing.T) {
+	objs := []map[string]any{
+		{"bool": false, "string": "", "list": []any{}, "object": map[string]any{}, "items": []any{map[string]any{"enabled": false}}},
+		{"bool": true, "string": "x", "list": []any{"x"}, "object": map[string]any{"x": "x"}, "items": []any{map[string]any{"enabled": true}}},
+	}
+
+	tests := []struct {
+		name     string
+		options  map[string]any
+		expected map[string]any
+	}{
+		{
+			name:     "override",
+			options:  map[string]any{},
+			expected: map[string]any{"bool": true, "string": "x", "list": []any{"x"}, "object": map[string]any{"x": "x"}, "items": []any{map[string]any{"enabled": true}}},
+		},
+		{
+			name:     "no override",
+			options:  map[string]any{"override": false},
+			expected: map[string]any{"bool": false, "string": "", "list": []any{}, "object": map[string]any{"x": "x"}, "items": []any{map[string]any{"enabled": false}}},
+		},
+		{
+			name:     "no override with sort_lists",
+			options:  map[string]any{"override": false, "sort_lists": true},
+			expected: map[string]any{"bool": false, "string": "", "list": []any{}, "object": map[string]any{"x": "x"}, "items": []any{map[string]any{"enabled": false}}},
+		},
+		{
+			name:     "no override with unrelated paths",
+			options:  map[string]any{"override": false, "paths": map[string]any{"other": map[string]any{"append_list": true}}},
+			expected: map[string]any{"bool": false, "string": "", "list": []any{}, "object": map[string]any{"x": "x"}, "items": []any{map[string]any{"enabled": false}}},
+		},
+		{
+			name:     "deep copied list elements are overridden",
+			options:  map[string]any{"override": false, "deep_copy_list": true},
+			expected: map[string]any{"bool": false, "string": "", "list": []any{}, "object": map[string]any{"x": "x"}, "items": []any{map[string]any{"enabled": true}}},
+		},
+		{
+			name:     "deep copied list elements are overridden with sort_lists",
+			options:  map[string]any{"override": false, "deep_copy_list": true, "sort_lists": true},
+			expected: map[string]any{"bool": false, "string": "", "list": []any{}, "object": map[string]any{"x": "x"}, "items": []any{map[string]any{"enabled": true}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := NewDefaultOptions()
+			assert.NoError(t, DecodeOptions(tt.options, opts))
+
+			merged, diags := merge(context.Background(), copyObjects(objs), nil, *opts)
+			assert.False(t, diags.HasError(), diags)
+			assert.Equal(t, tt.expected, merged)
+		})
+	}
+}
+
+func TestMergeConflicts(t *testing.T) {
+	tests := []struct {
+		name string
+		objs []map[string]any
+		err  string
+	}{
+		{
+			name: "conflicting value",
+			objs: []map[string]any{{"x": "1"}, {"x": "2"}},
+			err:  "x: set differently by arguments 1 and 2",
+		},
+		{
+			name: "value set again keeps its origin",
+			objs: []map[string]any{{"x": "1"}, {"x": "1"}, {"x": "2"}},
+			err:  "x: set differently by arguments 1 and 3",
+		},
+		{
+			name: "nested value set again keeps its origin",
+			objs: []map[string]any{{"x": map[string]any{"a": "1"}}, {"x": map[string]any{"a": "1"}}, {"x": map[string]any{"a": "2"}}},
+			err:  "x.a: set differently by arguments 1 and 3",
+		},
+		{
+			name: "several conflicting objects",
+			objs: []map[string]any{{"x": "1"}, {"y": "1"}, {"x": "2"}, {"x": "3"}},
+			err:  "x: set differently by arguments 1, 3 and 4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := NewDefaultOptions()
+			opts.OnConflict = OnConflictError
+
+			_, diags := merge(context.Background(), tt.objs, nil, *opts)
+			assert.True(t, diags.HasError())
+			assert.Contains(t, diags[0].Detail(), tt.err)
+		})
+	}
+}
+
+func TestMergeDirectivesInNewValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		objs     []map[string]any
+		expected map[string]any
+	}{
+		{
+			name: "deleted key of a new object",
+			objs: []map[string]any{
+				{"x": "1"},
+				{"a": map[string]any{"b": map[string]any{"$patch": "delete"}, "c": "1"}},
+			},
+			expected: map[string]any{"x": "1", "a": map[string]any{"c": "1"}},
+		},
+		{
+			name: "deleted key of the first object",
+			objs: []map[string]any{
+				{"a": map[string]any{"b": map[string]any{"c": map[string]any{"$patch": "delete"}}}},
+			},
+			expected: map[string]any{"a": map[string]any{"b": map[string]any{}}},
+		},
+		{
+			name: "retained keys of a new object",
+			objs: []map[string]any{
+				{"x": "1"},
+				{"a": map[string]any{"$retainKeys": []any{"b"}, "b": "1", "c": "1"}},
+			},
+			expected: map[string]any{"x": "1", "a": map[string]any{"b": "1"}},
+		},
+		{
+			name: "deleted elements of a new list",
+			objs: []map[string]any{
+				{"x": "1"},
+				{"a": map[string]any{"env": []any{map[string]any{"name": "A"}, map[string]any{"name": "B", "$patch": "delete"}}}},
+			},
+			expected: map[string]any{"x": "1", "a": map[string]any{"env": []any{map[string]any{"name": "A"}}}},
+		},
+		{
+			name: "deleted key of a replacing object",
+			objs: []map[string]any{
+				{"a": map[string]any{"b": "1"}},
+				{"a": map[string]any{"$patch": "replace", "c": map[string]any{"$patch": "delete"}, "d": "1"}},
+			},
+			expected: map[string]any{"a": map[string]any{"d": "1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, diags := merge(context.Background(), tt.objs, nil, *NewDefaultOptions())
+			assert.False(t, diags.HasError(), diags)
+			assert.Equal(t, tt.expected, merged)
+		})
+	}
+}
+
+func TestMergePathOptions(t *testing.T) {
+	objs := []map[string]any{
+		{"a": map[string]any{"list": []any{"x"}, "b": map[string]any{"list": []any{"x"}}}, "list": []any{"x"}},
+		{"a": map[string]any{"list": []any{"y"}, "b": map[string]any{"list": []any{"y"}}}, "list": []any{"y"}},
+	}
+
+	opts := NewDefaultOptions()
+	assert.NoError(t, DecodeOptions(map[string]any{
+		"union_lists": true,
+		"paths": map[string]any{
+			"a.**":   map[string]any{"append_list": true, "union_lists": false},
+			"a.b.**": map[string]any{"prepend_list": true, "append_list": nil},
+		},
+	}, opts))
+
+	merged, diags := merge(context.Background(), objs, nil, *opts)
+	assert.False(t, diags.HasError(), diags)
+	assert.Equal(t, map[string]any{
+		"a":    map[string]any{"list": []any{"x", "y"}, "b": map[string]any{"list": []any{"y", "x"}}},
+		"list": []any{"x", "y"},
+	}, merged)
+}
@@ -0,0 +1,71 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package deepmerge
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+
+	"github.com/lablabs/terraform-provider-lara-utils/internal/helpers"
+	"github.com/lablabs/terraform-provider-lara-utils/internal/pathexpr"
+)
+
+// Values of the type_mismatch option, controlling what happens when merged objects set values of different types.
+const (
+	TypeMismatchReplace = "replace"
+	TypeMismatchKeep    = "keep"
+	TypeMismatchError   = "error"
+)
+
+// typeName returns the name of the value type as used in Terraform, or an empty string for null and unknown values.
+func typeName(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "list"
+	case string:
+		return "string"
+	case *big.Float, float64:
+		return "number"
+	case bool:
+		return "bool"
+	case nil, *helpers.Unknown:
+		return ""
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// mismatches reports whether dst and src are non-null known values of different types, recording the mismatch
+// if the error mode is used.
+func (m merger) mismatches(p pathexpr.Path, dst, src any) bool {
+	dstType, srcType := typeName(dst), typeName(src)
+	if dstType == "" || srcType == "" || dstType == srcType {
+		return false
+	}
+
+	if m.TypeMismatch == TypeMismatchError {
+		m.state.mismatches = append(m.state.mismatches, fmt.Sprintf(
+			"  - %s: %s set by argument %d, %s set by argument %d",
+			p, dstType, m.state.origin(p)+1, srcType, m.arg+1,
+		))
+	}
+
+	return true
+}
+
+func (s *mergeState) mismatchDiagnostics() (diags diag.Diagnostics) {
+	if len(s.mismatches) > 0 {
+		sort.Strings(s.mismatches)
+		diags.Append(diag.NewErrorDiagnostic(
+			"type mismatch in merged objects",
+			fmt.Sprintf("found %d value(s) of different types:\n%s", len(s.mismatches), strings.Join(s.mismatches, "\n")),
+		))
+	}
+	return
+}
@@ -0,0 +1,39 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package deepmerge
+
+// deleteNulls removes keys with null values from the value and its nested objects in place, and null
+// elements from lists if NullDeleteElements is set.
+func (m merger) deleteNulls(v any) any {
+	switch vv := v.(type) {
+	case map[string]any:
+		for key, elem := range vv {
+			if elem == nil {
+				delete(vv, key)
+				continue
+			}
+			vv[key] = m.deleteNulls(elem)
+		}
+
+	case []any:
+		result := make([]any, 0, len(vv))
+		for _, elem := range vv {
+			if elem == nil && m.NullDeleteElements {
+				continue
+			}
+			result = append(result, m.deleteNulls(elem))
+		}
+		return result
+	}
+
+	return v
+}
+
+// withoutNulls returns the value with nulls removed by deleteNulls if NullDelete is set.
+func (m merger) withoutNulls(v any) any {
+	if !m.NullDelete {
+		return v
+	}
+	return m.deleteNulls(v)
+}
@@ -0,0 +1,264 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package deepmerge
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/lablabs/terraform-provider-lara-utils/internal/helpers"
+)
+
+// OptionType is the type of values accepted by a merging option, as named in Terraform.
+type OptionType string
+
+const (
+	OptionBool   OptionType = "bool"
+	OptionNumber OptionType = "number"
+	OptionString OptionType = "string"
+	// OptionStringList accepts a list of strings, or a single string with comma separated values.
+	OptionStringList OptionType = "list(string)"
+	// OptionPaths accepts an object of options objects, keyed by path patterns.
+	OptionPaths OptionType = "map(object)"
+	// OptionObject accepts an object of the nested options of the option.
+	OptionObject OptionType = "object"
+)
+
+// OptionSchema describes a merging option accepted by all merge functions.
+type OptionSchema struct {
+	Name string     `json:"name"`
+	Type OptionType `json:"type"`
+	// Values lists the allowed values of string options, empty if any value is allowed.
+	Values []string `json:"values,omitempty"`
+	// Options describes the nested options of object options.
+	Options []OptionSchema `json:"options,omitempty"`
+	// Functions lists the merge functions accepting the option, empty if all of them do.
+	Functions   []string `json:"functions,omitempty"`
+	Description string   `json:"description"`
+}
+
+var optionsSchema = []OptionSchema{
+	{Name: "override", Type: OptionBool, Description: "Later values replace earlier ones"},
+	{Name: "null_override", Type: OptionBool, Description: "Null values replace existing values"},
+	{Name: "null_delete", Type: OptionBool, Description: "Keys with null values are removed from the result"},
+	{Name: "null_delete_elements", Type: OptionBool, Description: "Null list elements are removed from the result with null_delete"},
+	{Name: "append_list", Type: OptionBool, Description: "Lists are concatenated instead of replaced"},
+	{Name: "deep_copy_list", Type: OptionBool, Description: "Lists are deeply merged element by element using override"},
+	{Name: "merge_by_index", Type: OptionBool, Description: "Lists are merged element by element at the same positions"},
+	{Name: "prepend_list", Type: OptionBool, Description: "Lists are concatenated with later elements first"},
+	{Name: "union_lists", Type: OptionBool, Description: "Lists are merged as sets (unique elements)"},
+	{Name: "prepend_union", Type: OptionBool, Description: "Lists are merged as sets with later elements first"},
+	{Name: "union_by", Type: OptionStringList, Description: "Lists of objects are merged as sets by the identity fields"},
+	{Name: "union_by_strategy", Type: OptionString, Values: []string{UnionByReplace, UnionByMerge}, Description: "Objects with the same identity replace or are merged into earlier ones"},
+	{Name: "sort_lists", Type: OptionBool, Description: "Merged lists are sorted"},
+	{Name: "sort_by", Type: OptionStringList, Description: "Fields ordering objects in sorted lists"},
+	{Name: "merge_key", Type: OptionStringList, Description: "Lists of objects are merged by matching key fields"},
+	{Name: "paths", Type: OptionPaths, Description: "Options overridden for values matching the path patterns"},
+	{Name: "atomic_paths", Type: OptionStringList, Description: "Values matching the path patterns are replaced as a whole"},
+	{Name: "max_depth", Type: OptionNumber, Description: "Values deeper than the depth are replaced as a whole"},
+	{Name: "directives", Type: OptionBool, Description: "Merge directives embedded in the objects are applied"},
+	{Name: "on_conflict", Type: OptionString, Values: []string{OnConflictOverride, OnConflictKeepFirst, OnConflictError, OnConflictWarn}, Description: "What happens when objects set a value differently"},
+	{Name: "type_mismatch", Type: OptionString, Values: []string{TypeMismatchReplace, TypeMismatchKeep, TypeMismatchError}, Description: "What happens when objects set values of different types"},
+	{Name: "profile", Type: OptionString, Values: []string{ProfileHelm, ProfileKubernetes, ProfileTerraform}, Description: "Preset of options for a common use case, overridden by other options"},
+	{Name: "labels", Type: OptionStringList, Functions: []string{"deep_merge_explain"}, Description: "Names of the merged objects in the explanation"},
+	{Name: "yaml", Type: OptionObject, Options: yamlOptionsSchema, Functions: []string{"yaml_deep_merge"}, Description: "Layout of the YAML result"},
+}
+
+var yamlOptionsSchema = []OptionSchema{
+	{Name: "indent", Type: OptionNumber, Description: "Spaces indenting nested values, from 2 to 9"},
+	{Name: "indent_sequences", Type: OptionBool, Description: "Lists nested in objects are indented"},
+	{Name: "line_width", Type: OptionNumber, Description: "Width to which long strings are folded, 0 for no folding"},
+	{Name: "flow_lists", Type: OptionNumber, Description: "Lists of up to this many scalars are written in flow style"},
+	{Name: "quote_style", Type: OptionString, Values: []string{helpers.YAMLSingleQuoted, helpers.YAMLDoubleQuoted}, Description: "Quotes of quoted strings"},
+	{Name: "quote_strings", Type: OptionBool, Description: "All single-line strings are quoted"},
+	{Name: "document_start", Type: OptionBool, Description: "The document starts with the --- marker"},
+	{Name: "empty_object", Type: OptionBool, Description: "An empty result is written as {}"},
+	{Name: "preserve_source", Type: OptionBool, Description: "Comments, key order and scalar styles of the documents are kept"},
+}
+
+// OptionsSchema returns the schema of the merging options shared by all merge functions.
+func OptionsSchema() []OptionSchema {
+	return slices.Clone(optionsSchema)
+}
+
+// checkOptions checks the keys and value types of an options object against the schema, accepting
+// the options of the named merge function and the options of all functions.
+func checkOptions(input any, function string) error {
+	obj, ok := input.(map[string]any)
+	if !ok {
+		return fmt.Errorf("options must be an object, got: %s", valueType(input))
+	}
+
+	schema := slices.DeleteFunc(slices.Clone(optionsSchema), func(option OptionSchema) bool {
+		return len(option.Functions) > 0 && !slices.Contains(option.Functions, function)
+	})
+	return checkObject(obj, schema, "")
+}
+
+// checkObject checks the keys and value types of the object against the schema, naming the options
+// with the prefix.
+func checkObject(obj map[string]any, schema []OptionSchema, prefix string) error {
+	names := make([]string, len(schema))
+	for i, option := range schema {
+		names[i] = prefix + option.Name
+	}
+
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		i := slices.Index(names, prefix+key)
+		if i < 0 {
+			return fmt.Errorf("unknown option %q%s", prefix+key, didYouMean(prefix+key, names))
+		}
+
+		option := schema[i]
+		option.Name = names[i]
+		if err := option.check(obj[key]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isObjectOption reports whether the named option is an object of nested options.
+func isObjectOption(name string) bool {
+	i := slices.IndexFunc(optionsSchema, func(option OptionSchema) bool { return option.Name == name })
+	return i >= 0 && optionsSchema[i].Type == OptionObject
+}
+
+// check checks the value of the option, null values are always accepted.
+func (o OptionSchema) check(value any) error {
+	if value == nil {
+		return nil
+	}
+
+	switch o.Type {
+	case OptionBool:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s must be a bool, got: %s", o.Name, valueType(value))
+		}
+
+	case OptionNumber:
+		n, ok := number(value)
+		if !ok {
+			return fmt.Errorf("%s must be a number, got: %s", o.Name, valueType(value))
+		}
+		if !n.IsInt() {
+			return fmt.Errorf("%s must be a whole number, got: %s", o.Name, n.Text('g', -1))
+		}
+		if _, acc := n.Int64(); acc != big.Exact {
+			return fmt.Errorf("%s is out of range, got: %s", o.Name, n.Text('f', 0))
+		}
+
+	case OptionString:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s must be a string, got: %s", o.Name, valueType(value))
+		}
+		if len(o.Values) > 0 && !slices.Contains(o.Values, s) {
+			return fmt.Errorf("%s must be one of %s, got: %q%s", o.Name, quoteValues(o.Values), s, didYouMean(s, o.Values))
+		}
+
+	case OptionStringList:
+		if _, ok := value.(string); ok {
+			return nil
+		}
+		list, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s must be a string or a list of strings, got: %s", o.Name, valueType(value))
+		}
+		for _, elem := range list {
+			if _, ok := elem.(string); !ok {
+				return fmt.Errorf("%s must be a list of strings, got element: %s", o.Name, valueType(elem))
+			}
+		}
+
+	case OptionPaths:
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s must be an object of options, got: %s", o.Name, valueType(value))
+		}
+		for expr, overrides := range obj {
+			if _, ok := overrides.(map[string]any); !ok {
+				return fmt.Errorf("invalid options for path %q: options must be an object, got: %s", expr, valueType(overrides))
+			}
+		}
+
+	case OptionObject:
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s must be an object of settings, got: %s", o.Name, valueType(value))
+		}
+		return checkObject(obj, o.Options, o.Name+".")
+	}
+
+	return nil
+}
+
+// valueType returns the name of the value type as used in Terraform.
+func valueType(v any) string {
+	if v == nil {
+		return "null"
+	}
+	return typeName(v)
+}
+
+// quoteValues formats the values like "a", "b" or "c".
+func quoteValues(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+}
+
+// didYouMean returns a suggestion of the candidate closest to the given name, or an empty string if none is close.
+func didYouMean(name string, candidates []string) string {
+	best, bestDistance := "", math.MaxInt
+	for _, candidate := range candidates {
+		if d := editDistance(name, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+
+	if bestDistance > max(2, len(name)/4) {
+		return ""
+	}
+	return fmt.Sprintf(", did you mean %q?", best)
+}
+
+// editDistance returns the Levenshtein distance of the strings.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
@@ -0,0 +1,446 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package deepmerge
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lablabs/terraform-provider-lara-utils/internal/helpers"
+)
+
+func TestDecodeOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		input any
+		err   string
+	}{
+		{
+			name:  "valid options",
+			input: map[string]any{"append_list": true, "merge_key": "name", "max_depth": float64(2), "on_conflict": "warn"},
+		},
+		{
+			name:  "null values",
+			input: map[string]any{"override": nil, "merge_key": nil},
+		},
+		{
+			name:  "unknown option with suggestion",
+			input: map[string]any{"apend_list": true},
+			err:   `unknown option "apend_list", did you mean "append_list"?`,
+		},
+		{
+			name:  "unknown option without suggestion",
+			input: map[string]any{"colors": true},
+			err:   `unknown option "colors"`,
+		},
+		{
+			name:  "wrong bool type",
+			input: map[string]any{"override": "yes"},
+			err:   `override must be a bool, got: string`,
+		},
+		{
+			name:  "wrong number type",
+			input: map[string]any{"max_depth": "2"},
+			err:   `max_depth must be a number, got: string`,
+		},
+		{
+			name:  "fractional number",
+			input: map[string]any{"max_depth": 1.5},
+			err:   `max_depth must be a whole number, got: 1.5`,
+		},
+		{
+			name:  "number out of range",
+			input: map[string]any{"max_depth": new(big.Float).SetPrec(512).SetFloat64(1e20)},
+			err:   `max_depth is out of range, got: 100000000000000000000`,
+		},
+		{
+			name:  "negative number",
+			input: map[string]any{"max_depth": new(big.Float).SetPrec(512).SetInt64(-1)},
+			err:   `max_depth must not be negative, got: -1`,
+		},
+		{
+			name:  "wrong list element type",
+			input: map[string]any{"merge_key": []any{"name", true}},
+			err:   `merge_key must be a list of strings, got element: bool`,
+		},
+		{
+			name:  "invalid value with suggestion",
+			input: map[string]any{"on_conflict": "eror"},
+			err:   `on_conflict must be one of "override", "keep_first", "error" or "warn", got: "eror", did you mean "error"?`,
+		},
+		{
+			name:  "unknown option in path options",
+			input: map[string]any{"paths": map[string]any{"spec.*": map[string]any{"union_list": true}}},
+			err:   `invalid options for path "spec.*": unknown option "union_list", did you mean "union_lists"?`,
+		},
+		{
+			name:  "wrong path options type",
+			input: map[string]any{"paths": map[string]any{"spec.*": true}},
+			err:   `invalid options for path "spec.*": options must be an object, got: bool`,
+		},
+		{
+			name:  "unknown profile",
+			input: map[string]any{"profile": "helmet"},
+			err:   `profile must be one of "helm", "kubernetes" or "terraform", got: "helmet", did you mean "helm"?`,
+		},
+		{
+			name:  "profile in path options",
+			input: map[string]any{"paths": map[string]any{"spec": map[string]any{"profile": "helm"}}},
+			err:   `invalid options for path "spec": profile can't be nested`,
+		},
+		{
+			name:  "options not an object",
+			input: []any{"append_list"},
+			err:   `options must be an object, got: list`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DecodeOptions(tt.input, NewDefaultOptions())
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDecodeYamlOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		function string
+		inputs   []any
+		expected YamlOptions
+		err      string
+	}{
+		{
+			name:     "settings",
+			function: "yaml_deep_merge",
+			inputs:   []any{map[string]any{"yaml": map[string]any{"indent": float64(4), "quote_style": "double", "preserve_source": true}}},
+			expected: YamlOptions{YAMLFormat: helpers.YAMLFormat{Indent: 4, QuoteStyle: "double"}, PreserveSource: true},
+		},
+		{
+			name:     "later settings override earlier ones",
+			function: "yaml_deep_merge",
+			inputs: []any{
+				map[string]any{"yaml": map[string]any{"indent": float64(4), "document_start": true}},
+				map[string]any{"yaml": map[string]any{"indent": float64(2), "line_width": nil}},
+				map[string]any{"yaml": nil},
+			},
+			expected: YamlOptions{YAMLFormat: helpers.YAMLFormat{Indent: 2, DocumentStart: true}},
+		},
+		{
+			name:   "other functions",
+			inputs: []any{map[string]any{"yaml": map[string]any{"indent": float64(4)}}},
+			err:    `unknown option "yaml"`,
+		},
+		{
+			name:     "unknown setting with suggestion",
+			function: "yaml_deep_merge",
+			inputs:   []any{map[string]any{"yaml": map[string]any{"indnet": float64(4)}}},
+			err:      `unknown option "yaml.indnet", did you mean "yaml.indent"?`,
+		},
+		{
+			name:     "wrong setting type",
+			function: "yaml_deep_merge",
+			inputs:   []any{map[string]any{"yaml": map[string]any{"document_start": "yes"}}},
+			err:      `yaml.document_start must be a bool, got: string`,
+		},
+		{
+			name:     "invalid setting value",
+			function: "yaml_deep_merge",
+			inputs:   []any{map[string]any{"yaml": map[string]any{"quote_style": "backtick"}}},
+			err:      `yaml.quote_style must be one of "single" or "double", got: "backtick"`,
+		},
+		{
+			name:     "indent out of range",
+			function: "yaml_deep_merge",
+			inputs:   []any{map[string]any{"yaml": map[string]any{"indent": float64(1)}}},
+			err:      `yaml.indent must be between 2 and 9, got: 1`,
+		},
+		{
+			name:     "setting out of range",
+			function: "yaml_deep_merge",
+			inputs:   []any{map[string]any{"yaml": map[string]any{"line_width": new(big.Float).SetPrec(512).SetFloat64(-1e20)}}},
+			err:      `yaml.line_width is out of range, got: -100000000000000000000`,
+		},
+		{
+			name:     "settings not an object",
+			function: "yaml_deep_merge",
+			inputs:   []any{map[string]any{"yaml": true}},
+			err:      `yaml must be an object of settings, got: bool`,
+		},
+		{
+			name:     "in path options",
+			function: "yaml_deep_merge",
+			inputs:   []any{map[string]any{"paths": map[string]any{"spec": map[string]any{"yaml": map[string]any{}}}}},
+			err:      `invalid options for path "spec": unknown option "yaml"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := NewDefaultOptions()
+			var err error
+			for _, input := range tt.inputs {
+				if err = DecodeFunctionOptions(tt.function, input, opts); err != nil {
+					break
+				}
+			}
+
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, opts.Yaml)
+		})
+	}
+}
+
+func TestDecodeLabels(t *testing.T) {
+	tests := []struct {
+		name     string
+		function string
+		input    any
+		expected []string
+		err      string
+	}{
+		{
+			name:     "labels",
+			function: "deep_merge_explain",
+			input:    map[string]any{"labels": []any{"defaults", "production"}},
+			expected: []string{"defaults", "production"},
+		},
+		{
+			name:  "other functions",
+			input: map[string]any{"labels": []any{"defaults"}},
+			err:   `unknown option "labels"`,
+		},
+		{
+			name:     "misspelled",
+			function: "deep_merge_explain",
+			input:    map[string]any{"lables": []any{"defaults"}},
+			err:      `unknown option "lables", did you mean "labels"?`,
+		},
+		{
+			name:     "wrong element type",
+			function: "deep_merge_explain",
+			input:    map[string]any{"labels": []any{"defaults", true}},
+			err:      `labels must be a list of strings, got element: bool`,
+		},
+		{
+			name:     "wrong type",
+			function: "deep_merge_explain",
+			input:    map[string]any{"labels": map[string]any{"a": "defaults"}},
+			err:      `labels must be a string or a list of strings, got: object`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := NewDefaultOptions()
+			err := DecodeFunctionOptions(tt.function, tt.input, opts)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, opts.Labels)
+		})
+	}
+}
+
+func TestDecodeNullOptions(t *testing.T) {
+	objs := []map[string]any{
+		{"a": "x", "spec": map[string]any{"b": "x"}},
+		{"a": "y", "spec": map[string]any{"b": "y"}},
+		{"a": nil},
+	}
+
+	tests := []struct {
+		name     string
+		options  []any
+		defaults bool
+		expected map[string]any
+	}{
+		{
+			name:     "null override keeps the default",
+			options:  []any{map[string]any{"override": nil}},
+			defaults: true,
+			expected: map[string]any{"a": nil, "spec": map[string]any{"b": "y"}},
+		},
+		{
+			name:     "null on_conflict keeps the default",
+			options:  []any{map[string]any{"on_conflict": nil}},
+			defaults: true,
+			expected: map[string]any{"a": nil, "spec": map[string]any{"b": "y"}},
+		},
+		{
+			name:     "null values keep earlier options",
+			options:  []any{map[string]any{"override": false}, map[string]any{"override": nil, "on_conflict": nil}},
+			expected: map[string]any{"a": "x", "spec": map[string]any{"b": "x"}},
+		},
+		{
+			name:     "null values keep profile options",
+			options:  []any{map[string]any{"profile": "helm", "null_delete": nil}},
+			expected: map[string]any{"spec": map[string]any{"b": "y"}},
+		},
+		{
+			name:     "null values in path options keep the options",
+			options:  []any{map[string]any{"paths": map[string]any{"spec": map[string]any{"override": nil}}}},
+			expected: map[string]any{"a": nil, "spec": map[string]any{"b": "y"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := NewDefaultOptions()
+			for _, options := range tt.options {
+				assert.NoError(t, DecodeOptions(options, opts))
+			}
+
+			if tt.defaults {
+				assert.Equal(t, NewDefaultOptions(), opts)
+			}
+
+			merged, diags := merge(context.Background(), copyObjects(objs), nil, *opts)
+			assert.False(t, diags.HasError(), diags)
+			assert.Equal(t, tt.expected, merged)
+		})
+	}
+}
+
+func TestOptionsSchema(t *testing.T) {
+	assertSchemaFields(t, OptionsSchema(), reflect.TypeFor[DeepMergeOptions](), "")
+}
+
+// assertSchemaFields asserts that the schema describes exactly the fields of the options struct,
+// including the fields of nested object options.
+func assertSchemaFields(t *testing.T, schema []OptionSchema, typ reflect.Type, prefix string) {
+	options := map[string]OptionSchema{}
+	for _, option := range schema {
+		options[prefix+option.Name] = option
+	}
+
+	var fields func(typ reflect.Type)
+	fields = func(typ reflect.Type) {
+		for i := range typ.NumField() {
+			field := typ.Field(i)
+			name, flags, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+			if flags == "squash" {
+				fields(field.Type)
+				continue
+			}
+
+			option, ok := options[prefix+name]
+			assert.True(t, ok, "option %q missing in the schema", prefix+name)
+			if option.Type == OptionObject {
+				assertSchemaFields(t, option.Options, field.Type, prefix+name+".")
+			}
+			delete(options, prefix+name)
+		}
+	}
+	fields(typ)
+
+	assert.Empty(t, options, "schema options missing in %s", typ.Name())
+}
+
+func TestProfiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []any
+		objs     []map[string]any
+		expected map[string]any
+	}{
+		{
+			name:    "helm",
+			options: []any{map[string]any{"profile": "helm"}},
+			objs: []map[string]any{
+				{"image": map[string]any{"repository": "nginx", "tag": "1.0"}, "args": []any{"a"}, "debug": true},
+				{"image": map[string]any{"tag": "1.1"}, "args": []any{"b"}, "debug": nil},
+			},
+			expected: map[string]any{"image": map[string]any{"repository": "nginx", "tag": "1.1"}, "args": []any{"b"}},
+		},
+		{
+			name:    "kubernetes",
+			options: []any{map[string]any{"profile": "kubernetes"}},
+			objs: []map[string]any{
+				{"spec": map[string]any{"template": map[string]any{"spec": map[string]any{"containers": []any{
+					map[string]any{"name": "app", "image": "app:1", "env": []any{map[string]any{"name": "A", "value": "1"}}},
+					map[string]any{"name": "sidecar", "image": "sidecar:1"},
+				}}}}},
+				{"spec": map[string]any{"template": map[string]any{"spec": map[string]any{"containers": []any{
+					map[string]any{"name": "app", "image": "app:2", "env": []any{map[string]any{"name": "B", "value": "2"}}},
+				}}}}},
+			},
+			expected: map[string]any{"spec": map[string]any{"template": map[string]any{"spec": map[string]any{"containers": []any{
+				map[string]any{"name": "app", "image": "app:2", "env": []any{map[string]any{"name": "A", "value": "1"}, map[string]any{"name": "B", "value": "2"}}},
+				map[string]any{"name": "sidecar", "image": "sidecar:1"},
+			}}}}},
+		},
+		{
+			name:    "terraform",
+			options: []any{map[string]any{"profile": "terraform"}},
+			objs: []map[string]any{
+				{"a": map[string]any{"x": float64(1)}, "b": "keep"},
+				{"a": map[string]any{"y": float64(2)}, "b": nil},
+			},
+			expected: map[string]any{"a": map[string]any{"x": float64(1), "y": float64(2)}, "b": nil},
+		},
+		{
+			name:    "explicit options override the profile",
+			options: []any{map[string]any{"profile": "helm", "append_list": true}},
+			objs: []map[string]any{
+				{"args": []any{"a"}, "debug": true},
+				{"args": []any{"b"}, "debug": nil},
+			},
+			expected: map[string]any{"args": []any{"a", "b"}},
+		},
+		{
+			name: "paths are added to the profile paths",
+			options: []any{
+				map[string]any{"profile": "kubernetes"},
+				map[string]any{"paths": map[string]any{"spec.template.spec.tolerations": map[string]any{"append_list": true}}},
+			},
+			objs: []map[string]any{
+				{"spec": map[string]any{"template": map[string]any{"spec": map[string]any{
+					"volumes":     []any{map[string]any{"name": "data", "emptyDir": map[string]any{}}},
+					"tolerations": []any{map[string]any{"key": "a"}},
+				}}}},
+				{"spec": map[string]any{"template": map[string]any{"spec": map[string]any{
+					"volumes":     []any{map[string]any{"name": "config", "configMap": map[string]any{"name": "app"}}},
+					"tolerations": []any{map[string]any{"key": "b"}},
+				}}}},
+			},
+			expected: map[string]any{"spec": map[string]any{"template": map[string]any{"spec": map[string]any{
+				"volumes": []any{
+					map[string]any{"name": "data", "emptyDir": map[string]any{}},
+					map[string]any{"name": "config", "configMap": map[string]any{"name": "app"}},
+				},
+				"tolerations": []any{map[string]any{"key": "a"}, map[string]any{"key": "b"}},
+			}}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := NewDefaultOptions()
+			for _, options := range tt.options {
+				assert.NoError(t, DecodeOptions(options, opts))
+			}
+
+			merged, diags := merge(context.Background(), tt.objs, nil, *opts)
+			assert.False(t, diags.HasError(), diags)
+			assert.Equal(t, tt.expected, merged)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package deepmerge
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/lablabs/terraform-provider-lara-utils/internal/pathexpr"
+)
+
+// pathOptions holds options overrides applied at paths matching the pattern.
+type pathOptions struct {
+	pattern pathexpr.Expression
+	// options are the decoded options, of which only the fields set by the overrides are applied
+	options DeepMergeOptions
+	// fields are the indexes of the DeepMergeOptions fields set by the overrides
+	fields []int
+}
+
+// optionFields are the indexes of the DeepMergeOptions fields by their option names.
+var optionFields = func() map[string]int {
+	typ := reflect.TypeFor[DeepMergeOptions]()
+	fields := make(map[string]int, typ.NumField())
+	for i := range typ.NumField() {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("mapstructure"), ",")
+		fields[name] = i
+	}
+	return fields
+}()
+
+// apply sets the options overridden at the path in opts.
+func (po pathOptions) apply(opts *DeepMergeOptions) {
+	dst := reflect.ValueOf(opts).Elem()
+	src := reflect.ValueOf(&po.options).Elem()
+	for _, i := range po.fields {
+		dst.Field(i).Set(src.Field(i))
+	}
+}
+
+// compilePaths parses the path patterns of the Paths option, ordered so that more specific
+// patterns are applied later and take precedence.
+func (opts DeepMergeOptions) compilePaths() ([]pathOptions, error) {
+	compiled := make([]pathOptions, 0, len(opts.Paths))
+
+	for expr, overrides := range opts.Paths {
+		pattern, err := pathexpr.Parse(expr)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range []string{"paths", "atomic_paths", "profile"} {
+			if _, ok := overrides[name]; ok {
+				return nil, fmt.Errorf("invalid options for path %q: %s can't be nested", expr, name)
+			}
+		}
+
+		scratch := opts
+		scratch.Paths = nil
+		if err := DecodeOptions(overrides, &scratch); err != nil {
+			return nil, fmt.Errorf("invalid options for path %q: %w", expr, err)
+		}
+
+		fields := []int{}
+		for name, value := range overrides {
+			if value != nil {
+				fields = append(fields, optionFields[name])
+			}
+		}
+
+		compiled = append(compiled, pathOptions{pattern: pattern, options: scratch, fields: fields})
+	}
+
+	sort.Slice(compiled, func(i, j int) bool {
+		if wi, wj := compiled[i].pattern.Wildcards(), compiled[j].pattern.Wildcards(); wi != wj {
+			return wi > wj
+		}
+		return compiled[i].pattern.String() < compiled[j].pattern.String()
+	})
+
+	return compiled, nil
+}
+
+// compileAtomicPaths parses the path patterns of the AtomicPaths option.
+func (opts DeepMergeOptions) compileAtomicPaths() ([]pathexpr.Expression, error) {
+	compiled := make([]pathexpr.Expression, len(opts.AtomicPaths))
+
+	for i, expr := range opts.AtomicPaths {
+		pattern, err := pathexpr.Parse(expr)
+		if err != nil {
+			return nil, err
+		}
+		compiled[i] = pattern
+	}
+
+	return compiled, nil
+}
@@ -0,0 +1,97 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package deepmerge
+
+import "fmt"
+
+// Values of the profile option, naming presets of options for common use cases.
+const (
+	ProfileHelm       = "helm"
+	ProfileKubernetes = "kubernetes"
+	ProfileTerraform  = "terraform"
+)
+
+// profiles holds the options of every profile, in the same form as options objects passed to the functions.
+var profiles = map[string]map[string]any{
+	// Like Helm coalescing values: objects are merged, lists replaced and keys set to null removed.
+	ProfileHelm: withReplacedLists(map[string]any{
+		"override":      true,
+		"null_override": true,
+		"null_delete":   true,
+	}),
+
+	// Like Kubernetes strategic merge patch: lists of well-known objects are merged by their merge keys,
+	// other lists replaced and keys set to null removed.
+	ProfileKubernetes: withReplacedLists(map[string]any{
+		"override":      true,
+		"null_override": true,
+		"null_delete":   true,
+		"directives":    true,
+		"paths":         kubernetesPaths(),
+	}),
+
+	// Like the built-in merge function at the top level, where later values replace earlier ones including
+	// nulls, but merging objects below it.
+	ProfileTerraform: withReplacedLists(map[string]any{
+		"override":      true,
+		"null_override": true,
+		"null_delete":   false,
+	}),
+}
+
+// withReplacedLists adds options disabling all list merging strategies, so lists are replaced.
+func withReplacedLists(options map[string]any) map[string]any {
+	for _, name := range []string{"append_list", "prepend_list", "deep_copy_list", "merge_by_index", "union_lists", "prepend_union"} {
+		options[name] = false
+	}
+	options["merge_key"] = []any{}
+	options["union_by"] = []any{}
+	return options
+}
+
+// kubernetesPaths returns path options merging lists of Kubernetes pod templates by their merge keys, for
+// pods, workloads with a pod template and cron jobs, and lists of service ports.
+func kubernetesPaths() map[string]any {
+	paths := map[string]any{
+		"spec.ports": map[string]any{"merge_key": "port"},
+	}
+
+	podLists := map[string]string{
+		"containers":                "name",
+		"initContainers":            "name",
+		"ephemeralContainers":       "name",
+		"volumes":                   "name",
+		"imagePullSecrets":          "name",
+		"hostAliases":               "ip",
+		"topologySpreadConstraints": "topologyKey",
+	}
+	containerLists := map[string]string{
+		"env":           "name",
+		"ports":         "containerPort",
+		"volumeMounts":  "mountPath",
+		"volumeDevices": "devicePath",
+	}
+
+	for _, podSpec := range []string{"spec", "spec.template.spec", "spec.jobTemplate.spec.template.spec"} {
+		for field, key := range podLists {
+			paths[podSpec+"."+field] = map[string]any{"merge_key": key}
+		}
+		for _, containers := range []string{"containers", "initContainers", "ephemeralContainers"} {
+			for field, key := range containerLists {
+				paths[fmt.Sprintf("%s.%s[*].%s", podSpec, containers, field)] = map[string]any{"merge_key": key}
+			}
+		}
+	}
+
+	return paths
+}
+
+// applyProfile sets the options of the named profile, validated by checkOptions.
+func (opts *DeepMergeOptions) applyProfile(name string) error {
+	profile, ok := profiles[name]
+	if !ok {
+		return fmt.Errorf("unknown profile %q", name)
+	}
+	return decodeOptions(profile, opts)
+}
@@ -0,0 +1,191 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package deepmerge
+
+import (
+	"reflect"
+	"slices"
+	"unsafe"
+
+	"github.com/lablabs/terraform-provider-lara-utils/internal/pathexpr"
+)
+
+// provenance records the history of every value stored in the merged objects, explaining where the merged
+// values came from. Histories are kept by the identity of the objects holding the values, and elements of
+// lists are matched by their values, so histories follow the values when lists are combined, reordered
+// or filtered.
+type provenance struct {
+	slots map[unsafe.Pointer]map[string]*valueHistory
+}
+
+// valueHistory is the history of a stored value.
+type valueHistory struct {
+	// value is the stored value, used to match list elements.
+	value any
+	// entries are the different values stored at the same place, each with the index of the merged object
+	// which set it, ending with the current value.
+	entries []sourcedValue
+	// elements are the histories of the elements of a list value.
+	elements []*valueHistory
+}
+
+type sourcedValue struct {
+	arg   int
+	value any
+}
+
+func newProvenance() *provenance {
+	return &provenance{slots: map[unsafe.Pointer]map[string]*valueHistory{}}
+}
+
+// objectSlots returns the histories of the values of the object, by their keys.
+func (pv *provenance) objectSlots(obj map[string]any) map[string]*valueHistory {
+	id := reflect.ValueOf(obj).UnsafePointer()
+	slots, ok := pv.slots[id]
+	if !ok {
+		slots = map[string]*valueHistory{}
+		pv.slots[id] = slots
+	}
+	return slots
+}
+
+// record records the value stored at the key of the object by the merged object with the index arg.
+func (pv *provenance) record(obj map[string]any, key string, value any, arg int) {
+	slots := pv.objectSlots(obj)
+	slots[key] = pv.follow(slots[key], value, arg)
+}
+
+// follow returns the history of the value replacing the value with the previous history, which may be nil.
+// Values equal to the previous value keep its history, nested values continue the histories of the values
+// at the same places in the previous value.
+func (pv *provenance) follow(previous *valueHistory, value any, arg int) *valueHistory {
+	h := &valueHistory{value: value}
+	switch {
+	case previous == nil:
+		h.entries = []sourcedValue{{arg: arg, value: deepCopy(value)}}
+	case equalValues(previous.value, value):
+		h.entries = previous.entries
+	default:
+		h.entries = append(slices.Clip(previous.entries), sourcedValue{arg: arg, value: deepCopy(value)})
+	}
+
+	switch vv := value.(type) {
+	case map[string]any:
+		var previousSlots map[string]*valueHistory
+		if previous != nil {
+			if obj, ok := previous.value.(map[string]any); ok {
+				previousSlots = pv.objectSlots(obj)
+			}
+		}
+
+		// objects already stored keep the histories of their values
+		slots := pv.objectSlots(vv)
+		for key, elem := range vv {
+			if _, ok := slots[key]; !ok {
+				slots[key] = pv.follow(previousSlots[key], elem, arg)
+			}
+		}
+
+	case []any:
+		var previousElements []*valueHistory
+		if previous != nil {
+			if _, ok := previous.value.([]any); ok {
+				previousElements = previous.elements
+			}
+		}
+
+		matched := matchElements(previousElements, vv)
+		h.elements = make([]*valueHistory, len(vv))
+		for i, elem := range vv {
+			h.elements[i] = pv.follow(matched[i], elem, arg)
+		}
+	}
+
+	return h
+}
+
+// matchElements returns the histories of the previous elements matching the list elements. Elements are
+// matched to equal previous elements at the same positions first, then to equal previous elements at any
+// position, and the remaining elements to unmatched previous elements at the same positions, which they
+// replaced. Elements without any matching previous element are nil.
+func matchElements(previous []*valueHistory, elems []any) []*valueHistory {
+	matched := make([]*valueHistory, len(elems))
+	used := make([]bool, len(previous))
+
+	for i, elem := range elems {
+		if i < len(previous) && equalValues(previous[i].value, elem) {
+			matched[i], used[i] = previous[i], true
+		}
+	}
+
+	for i, elem := range elems {
+		if matched[i] != nil {
+			continue
+		}
+		for j, h := range previous {
+			if !used[j] && equalValues(h.value, elem) {
+				matched[i], used[j] = h, true
+				break
+			}
+		}
+	}
+
+	for i := range elems {
+		if matched[i] == nil && i < len(previous) && !used[i] {
+			matched[i], used[i] = previous[i], true
+		}
+	}
+
+	return matched
+}
+
+// leaves returns the histories of all values of the merged object which aren't non-empty objects or lists,
+// by their paths. Values without a recorded history take the history of the closest parent value.
+func (pv *provenance) leaves(merged map[string]any) map[string][]sourcedValue {
+	leaves := map[string][]sourcedValue{}
+	pv.collectLeaves(merged, nil, pathexpr.Path{}, nil, leaves)
+	return leaves
+}
+
+func (pv *provenance) collectLeaves(v any, h *valueHistory, p pathexpr.Path, inherited []sourcedValue, leaves map[string][]sourcedValue) {
+	if h != nil {
+		inherited = h.entries
+	}
+
+	switch vv := v.(type) {
+	case map[string]any:
+		if len(vv) > 0 {
+			slots := pv.objectSlots(vv)
+			for key, elem := range vv {
+				pv.collectLeaves(elem, slots[key], p.Child(key), inherited, leaves)
+			}
+			return
+		}
+
+	case []any:
+		if len(vv) > 0 {
+			var previous []*valueHistory
+			if h != nil {
+				previous = h.elements
+			}
+			for i, elem := range matchElements(previous, vv) {
+				pv.collectLeaves(vv[i], elem, p.Index(i), inherited, leaves)
+			}
+			return
+		}
+	}
+
+	if len(p) > 0 && len(inherited) > 0 {
+		leaves[p.String()] = inherited
+	}
+}
+
+// store stores a value combining the existing value and the value of the merged object at the key. Unlike
+// set, the origin tracked for conflicts isn't changed, as the nested values keep their own origins.
+func (m merger) store(dst map[string]any, key string, value any) {
+	if m.state.provenance != nil {
+		m.state.provenance.record(dst, key, value, m.arg)
+	}
+	dst[key] = value
+}
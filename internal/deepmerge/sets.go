@@ -0,0 +1,77 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package deepmerge
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+
+	"github.com/lablabs/terraform-provider-lara-utils/internal/pathexpr"
+)
+
+// isSet reports whether any of the merged objects has a set at the path. Sets are merged as unions
+// of their elements, whatever the list options are.
+func (m merger) isSet(p pathexpr.Path) bool {
+	for _, hint := range m.hints {
+		if _, ok := typeAt(hint, p).(basetypes.SetType); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// typeAt returns the type of the value at the path of a value of the type t, or nil if the type doesn't
+// have the path.
+func typeAt(t attr.Type, p pathexpr.Path) attr.Type {
+	for _, seg := range p {
+		switch tt := t.(type) {
+		case basetypes.ObjectType:
+			if seg.IsIndex {
+				return nil
+			}
+			t = tt.AttrTypes[seg.Key]
+		case basetypes.MapType:
+			if seg.IsIndex {
+				return nil
+			}
+			t = tt.ElemType
+		case basetypes.ListType:
+			if !seg.IsIndex {
+				return nil
+			}
+			t = tt.ElemType
+		case basetypes.SetType:
+			if !seg.IsIndex {
+				return nil
+			}
+			t = tt.ElemType
+		case basetypes.TupleType:
+			if !seg.IsIndex || seg.Index >= len(tt.ElemTypes) {
+				return nil
+			}
+			t = tt.ElemTypes[seg.Index]
+		default:
+			return nil
+		}
+	}
+	return t
+}
+
+// hasSets reports whether the type is or contains a set type.
+func hasSets(t attr.Type) bool {
+	switch tt := t.(type) {
+	case basetypes.SetType:
+		return true
+	case attr.TypeWithElementType:
+		return hasSets(tt.ElementType())
+	case attr.TypeWithAttributeTypes:
+		return slices.ContainsFunc(slices.Collect(maps.Values(tt.AttributeTypes())), hasSets)
+	case attr.TypeWithElementTypes:
+		return slices.ContainsFunc(tt.ElementTypes(), hasSets)
+	}
+	return false
+}
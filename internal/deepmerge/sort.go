@@ -0,0 +1,178 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package deepmerge
+
+import (
+	"cmp"
+	"math/big"
+	"slices"
+
+	"github.com/lablabs/terraform-provider-lara-utils/internal/helpers"
+	"github.com/lablabs/terraform-provider-lara-utils/internal/pathexpr"
+)
+
+// sortsLists reports whether any list can be sorted, globally or by path options.
+func (opts DeepMergeOptions) sortsLists() bool {
+	if opts.SortLists {
+		return true
+	}
+
+	for _, overrides := range opts.Paths {
+		if sort, ok := overrides["sort_lists"].(bool); ok && sort {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sortLists sorts the lists of the merged value in place, if enabled by SortLists at their paths. Lists whose
+// order depends on unknown values are replaced by unknown values, so the returned value must be used.
+func (m merger) sortLists(v any, p pathexpr.Path) any {
+	switch vv := v.(type) {
+	case map[string]any:
+		for key, elem := range vv {
+			vv[key] = m.sortLists(elem, m.child(p, key))
+		}
+
+	case []any:
+		for i, elem := range vv {
+			vv[i] = m.sortLists(elem, m.element(p, i, elem))
+		}
+
+		if lt := m.at(p); lt.SortLists {
+			if lt.sortsUnknown(vv) {
+				return helpers.NewUnknown()
+			}
+			lt.sortList(vv)
+		}
+	}
+
+	return v
+}
+
+// sortList sorts the list elements by type first: null, bool, number, string, list and object. Strings are
+// sorted naturally, so "node2" precedes "node10", and objects by the SortBy fields. Elements which can't be
+// ordered, e.g. objects missing any of the SortBy fields, keep their relative order.
+func (m merger) sortList(list []any) {
+	fields := make([]pathexpr.Path, len(m.SortBy))
+	for i, field := range m.SortBy {
+		fields[i], _ = pathexpr.ParsePath(field) // validated by DecodeOptions
+	}
+
+	slices.SortStableFunc(list, func(a, b any) int {
+		if c := cmp.Compare(typeRank(a), typeRank(b)); c != 0 {
+			return c
+		}
+
+		if _, ok := a.(map[string]any); !ok {
+			return compareScalars(a, b)
+		}
+
+		aKey, bKey := elementIdentity(a, fields), elementIdentity(b, fields)
+		switch {
+		case len(fields) == 0 || (aKey == nil && bKey == nil):
+			return 0
+		case aKey == nil:
+			return 1
+		case bKey == nil:
+			return -1
+		}
+
+		for i := range aKey {
+			if c := cmp.Compare(typeRank(aKey[i]), typeRank(bKey[i])); c != 0 {
+				return c
+			}
+			if c := compareScalars(aKey[i], bKey[i]); c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+}
+
+func typeRank(v any) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case *big.Float, float64:
+		return 2
+	case string:
+		return 3
+	case []any:
+		return 4
+	default:
+		return 5
+	}
+}
+
+// compareScalars compares values of the same type, returning 0 for values which aren't scalars.
+func compareScalars(a, b any) int {
+	switch av := a.(type) {
+	case bool:
+		bv := b.(bool) //nolint:forcetypeassert
+		switch {
+		case av == bv:
+			return 0
+		case !av:
+			return -1
+		default:
+			return 1
+		}
+	case *big.Float, float64:
+		an, _ := number(av)
+		bn, _ := number(b)
+		return an.Cmp(bn)
+	case string:
+		return compareNatural(av, b.(string)) //nolint:forcetypeassert
+	}
+	return 0
+}
+
+// compareNatural compares strings treating runs of digits as numbers.
+func compareNatural(a, b string) int {
+	for a != "" && b != "" {
+		aDigits, bDigits := digitPrefix(a), digitPrefix(b)
+
+		if aDigits == "" || bDigits == "" {
+			if c := cmp.Compare(a[0], b[0]); c != 0 {
+				return c
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+
+		// compare numbers by length of their significant digits first, then lexically
+		aNum, bNum := trimZeros(aDigits), trimZeros(bDigits)
+		if c := cmp.Compare(len(aNum), len(bNum)); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(aNum, bNum); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(len(aDigits), len(bDigits)); c != 0 {
+			return c
+		}
+		a, b = a[len(aDigits):], b[len(bDigits):]
+	}
+
+	return cmp.Compare(len(a), len(b))
+}
+
+func digitPrefix(s string) string {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return s[:i]
+}
+
+func trimZeros(s string) string {
+	for len(s) > 1 && s[0] == '0' {
+		s = s[1:]
+	}
+	return s
+}
@@ -0,0 +1,93 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package deepmerge
+
+import (
+	"slices"
+
+	"github.com/lablabs/terraform-provider-lara-utils/internal/helpers"
+	"github.com/lablabs/terraform-provider-lara-utils/internal/pathexpr"
+)
+
+// mergesUnknown reports whether merging the values depends on an unknown value, i.e. one of them is unknown
+// and the other is an object or a list it would be merged with, so the merged value is unknown too.
+func (m merger) mergesUnknown(p pathexpr.Path, dst, src any) bool {
+	mergeable := func(v any) bool {
+		_, isList := v.([]any)
+		return isObject(v) || isList && m.mergesLists(p)
+	}
+	return helpers.IsUnknown(dst) && mergeable(src) || helpers.IsUnknown(src) && mergeable(dst)
+}
+
+// mergesUnknownObject reports whether one of the values is unknown and the other is an object, which are merged
+// when both are objects, so the merged value is unknown.
+func mergesUnknownObject(dst, src any) bool {
+	return helpers.IsUnknown(dst) && isObject(src) || helpers.IsUnknown(src) && isObject(dst)
+}
+
+// mergesLists reports whether lists at the path are merged with each other instead of being replaced.
+func (m merger) mergesLists(p pathexpr.Path) bool {
+	return m.AppendList || m.PrependList || m.UnionLists || m.PrependUnion || m.MergeByIndex || m.DeepCopyList ||
+		len(m.MergeKey) > 0 || len(m.UnionBy) > 0 || m.isSet(p)
+}
+
+// mergesUnknownElements reports whether merging the lists depends on unknown elements, e.g. when unknown
+// elements may or may not be duplicates of other elements, so the merged list is unknown.
+func (m merger) mergesUnknownElements(p pathexpr.Path, dst, src []any) bool {
+	if !slices.ContainsFunc(dst, helpers.ContainsUnknown) && !slices.ContainsFunc(src, helpers.ContainsUnknown) {
+		return false
+	}
+	elems := slices.Concat(dst, src)
+
+	switch {
+	case len(m.MergeKey) > 0:
+		// unknown elements may be objects with matching keys
+		for _, elem := range elems {
+			obj, ok := elem.(map[string]any)
+			if !ok && helpers.IsUnknown(elem) {
+				return true
+			}
+			for _, field := range m.MergeKey {
+				if helpers.ContainsUnknown(obj[field]) {
+					return true
+				}
+			}
+		}
+
+	case len(m.UnionBy) > 0:
+		fields := make([]pathexpr.Path, len(m.UnionBy))
+		for i, field := range m.UnionBy {
+			fields[i], _ = pathexpr.ParsePath(field) // validated by DecodeOptions
+		}
+
+		// elements without identity are compared by equality
+		for _, elem := range elems {
+			identity := elementIdentity(elem, fields)
+			if identity == nil && helpers.ContainsUnknown(elem) || slices.ContainsFunc(identity, helpers.ContainsUnknown) {
+				return true
+			}
+		}
+
+	case m.UnionLists || m.PrependUnion || m.isSet(p):
+		return true
+	}
+
+	return false
+}
+
+// sortsUnknown reports whether the order of the sorted list depends on unknown values, i.e. it contains unknown
+// elements or objects with unknown SortBy fields.
+func (m merger) sortsUnknown(list []any) bool {
+	fields := make([]pathexpr.Path, len(m.SortBy))
+	for i, field := range m.SortBy {
+		fields[i], _ = pathexpr.ParsePath(field) // validated by DecodeOptions
+	}
+
+	for _, elem := range list {
+		if helpers.IsUnknown(elem) || slices.ContainsFunc(elementIdentity(elem, fields), helpers.ContainsUnknown) {
+			return true
+		}
+	}
+	return false
+}
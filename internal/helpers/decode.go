@@ -8,11 +8,15 @@ package helpers
 import (
 	"context"
 	"fmt"
+	"maps"
 	"math/big"
+	"slices"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 )
 
 func DecodeMapping(ctx context.Context, m map[string]any) (attr.Value, diag.Diagnostics) {
@@ -54,6 +58,9 @@ func DecodeScalar(ctx context.Context, m any) (value attr.Value, diags diag.Diag
 	case nil:
 		value = types.DynamicNull()
 
+	case *big.Float:
+		value = types.NumberValue(v)
+
 	case float64:
 		value = types.NumberValue(big.NewFloat(float64(v)))
 
@@ -69,9 +76,230 @@ func DecodeScalar(ctx context.Context, m any) (value attr.Value, diags diag.Diag
 	case map[string]any:
 		return DecodeMapping(ctx, v)
 
+	case *Unknown:
+		return decodeUnknown(ctx, v)
+
 	default:
 		diags.Append(diag.NewErrorDiagnostic("failed to decode", fmt.Sprintf("unexpected type: %T for value %#v", v, v)))
 	}
 
 	return
 }
+
+// DecodeTyped decodes the value like DecodeScalar, using the types of the values it was merged from as hints
+// to rebuild collections: maps, lists and sets are rebuilt where all hints are collections of the same kind and
+// the decoded elements have the same type, objects and tuples are returned otherwise. Null values take the type
+// of the values they were merged from, if they have the same one.
+func DecodeTyped(ctx context.Context, v any, hints []attr.Type) (attr.Value, diag.Diagnostics) {
+	switch vv := v.(type) {
+	case map[string]any:
+		return decodeTypedMapping(ctx, vv, hints)
+
+	case []any:
+		return decodeTypedSequence(ctx, vv, hints)
+
+	case nil:
+		if t := commonType(knownTypes(hints)); t != nil {
+			return valueOfType(ctx, t, nil)
+		}
+		return types.DynamicNull(), nil
+
+	case *Unknown:
+		// values which became unknown by merging take the type of the merged values, if they have the same one
+		if _, ok := vv.Type.(basetypes.DynamicType); ok {
+			if t := commonType(knownTypes(hints)); t != nil {
+				return decodeUnknown(ctx, &Unknown{Type: t})
+			}
+		}
+		return decodeUnknown(ctx, vv)
+
+	default:
+		return DecodeScalar(ctx, v)
+	}
+}
+
+func decodeTypedMapping(ctx context.Context, m map[string]any, hints []attr.Type) (attr.Value, diag.Diagnostics) {
+	// empty objects, like {} layers, have no keys constraining the type of the merged mapping
+	hints = slices.DeleteFunc(slices.Clone(hints), isEmptyObject)
+
+	vm := make(map[string]attr.Value, len(m))
+	tm := make(map[string]attr.Type, len(m))
+
+	for k, v := range m {
+		var elemHints []attr.Type
+		for _, hint := range hints {
+			switch h := hint.(type) {
+			case basetypes.MapType:
+				elemHints = append(elemHints, h.ElemType)
+			case basetypes.ObjectType:
+				if t, ok := h.AttrTypes[k]; ok {
+					elemHints = append(elemHints, t)
+				}
+			}
+		}
+
+		vv, diags := DecodeTyped(ctx, v, elemHints)
+		if diags.HasError() {
+			return nil, diags
+		}
+
+		vm[k] = vv
+		tm[k] = vv.Type(ctx)
+	}
+
+	if allOf[basetypes.MapType](hints) {
+		if elemType := commonElementType(hints, slices.Collect(maps.Values(tm))); elemType != nil {
+			return types.MapValue(elemType, vm)
+		}
+	}
+
+	return types.ObjectValue(tm, vm)
+}
+
+func decodeTypedSequence(ctx context.Context, s []any, hints []attr.Type) (attr.Value, diag.Diagnostics) {
+	vl := make([]attr.Value, len(s))
+	tl := make([]attr.Type, len(s))
+
+	for i, v := range s {
+		var elemHints []attr.Type
+		for _, hint := range hints {
+			switch h := hint.(type) {
+			case basetypes.ListType:
+				elemHints = append(elemHints, h.ElemType)
+			case basetypes.SetType:
+				elemHints = append(elemHints, h.ElemType)
+			case basetypes.TupleType:
+				if i < len(h.ElemTypes) {
+					elemHints = append(elemHints, h.ElemTypes[i])
+				}
+			}
+		}
+
+		vv, diags := DecodeTyped(ctx, v, elemHints)
+		if diags.HasError() {
+			return nil, diags
+		}
+
+		vl[i] = vv
+		tl[i] = vv.Type(ctx)
+	}
+
+	switch {
+	case allOf[basetypes.ListType](hints):
+		if elemType := commonElementType(hints, tl); elemType != nil {
+			return types.ListValue(elemType, vl)
+		}
+
+	case isSet(hints):
+		// sets are merged as unions, but other list options may still produce duplicates, which sets can't hold
+		if elemType := commonElementType(hints, tl); elemType != nil && distinct(vl) {
+			return types.SetValue(elemType, vl)
+		}
+	}
+
+	return types.TupleValue(tl, vl)
+}
+
+// allOf reports whether there are hints and all of them are of the type T.
+func allOf[T attr.Type](hints []attr.Type) bool {
+	for _, hint := range hints {
+		if _, ok := hint.(T); !ok {
+			return false
+		}
+	}
+	return len(hints) > 0
+}
+
+// isSet reports whether any of the hints is a set and the others are lists or tuples, i.e. the value was merged
+// from a set and other sequences.
+func isSet(hints []attr.Type) bool {
+	set := false
+	for _, hint := range hints {
+		switch hint.(type) {
+		case basetypes.SetType:
+			set = true
+		case basetypes.ListType, basetypes.TupleType:
+		default:
+			return false
+		}
+	}
+	return set
+}
+
+// commonElementType returns the type shared by all decoded elements, or by the element types of the collection
+// hints if there are no elements. It returns nil if the types differ or the type contains the dynamic type,
+// which collection elements can't have.
+func commonElementType(hints []attr.Type, elemTypes []attr.Type) attr.Type {
+	if len(elemTypes) == 0 {
+		for _, hint := range hints {
+			if t, ok := hint.(attr.TypeWithElementType); ok {
+				elemTypes = append(elemTypes, t.ElementType())
+			}
+		}
+	}
+
+	return commonType(elemTypes)
+}
+
+func isEmptyObject(t attr.Type) bool {
+	o, ok := t.(basetypes.ObjectType)
+	return ok && len(o.AttrTypes) == 0
+}
+
+// knownTypes returns the hints except the dynamic type, which is the type of null literals.
+func knownTypes(hints []attr.Type) []attr.Type {
+	return slices.DeleteFunc(slices.Clone(hints), func(t attr.Type) bool {
+		_, ok := t.(basetypes.DynamicType)
+		return ok
+	})
+}
+
+// valueOfType returns the null or unknown value of the type, val being nil or tftypes.UnknownValue.
+func valueOfType(ctx context.Context, t attr.Type, val any) (attr.Value, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	value, err := t.ValueFromTerraform(ctx, tftypes.NewValue(t.TerraformType(ctx), val))
+	if err != nil {
+		diags.Append(diag.NewErrorDiagnostic("failed to decode", fmt.Sprintf("value %v of type %s: %s", val, t, err)))
+	}
+	return value, diags
+}
+
+// commonType returns the type of all hints, or nil if there are none, they differ or contain the dynamic type.
+func commonType(hints []attr.Type) attr.Type {
+	if len(hints) == 0 || isDynamic(hints[0]) {
+		return nil
+	}
+	for _, t := range hints[1:] {
+		if !t.Equal(hints[0]) {
+			return nil
+		}
+	}
+	return hints[0]
+}
+
+// isDynamic reports whether the type is or contains the dynamic type.
+func isDynamic(t attr.Type) bool {
+	switch tt := t.(type) {
+	case basetypes.DynamicType:
+		return true
+	case attr.TypeWithElementType:
+		return isDynamic(tt.ElementType())
+	case attr.TypeWithAttributeTypes:
+		return slices.ContainsFunc(slices.Collect(maps.Values(tt.AttributeTypes())), isDynamic)
+	case attr.TypeWithElementTypes:
+		return slices.ContainsFunc(tt.ElementTypes(), isDynamic)
+	}
+	return false
+}
+
+func distinct(values []attr.Value) bool {
+	for i := range values {
+		for j := range i {
+			if values[i].Equal(values[j]) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -36,6 +36,12 @@ func TestDecodeScalar(t *testing.T) {
 			expected: types.NumberValue(big.NewFloat(3.14)),
 			hasError: false,
 		},
+		{
+			name:     "big float value",
+			input:    mustParseNumber("123456789012345678901234567890"),
+			expected: types.NumberValue(mustParseNumber("123456789012345678901234567890")),
+			hasError: false,
+		},
 		{
 			name:     "bool value",
 			input:    true,
@@ -90,6 +96,18 @@ func TestDecodeScalar(t *testing.T) {
 			}(),
 			hasError: false,
 		},
+		{
+			name:     "unknown value",
+			input:    &Unknown{Type: types.ListType{ElemType: types.StringType}},
+			expected: types.ListUnknown(types.StringType),
+			hasError: false,
+		},
+		{
+			name:     "unknown value of unknown type",
+			input:    NewUnknown(),
+			expected: types.DynamicUnknown(),
+			hasError: false,
+		},
 		{
 			name:     "unexpected type",
 			input:    struct{}{},
@@ -110,3 +128,181 @@ func TestDecodeScalar(t *testing.T) {
 		})
 	}
 }
+
+func TestDecodeTyped(t *testing.T) {
+	ctx := context.Background()
+
+	stringMap := types.MapType{ElemType: types.StringType}
+	stringList := types.ListType{ElemType: types.StringType}
+	stringSet := types.SetType{ElemType: types.StringType}
+
+	tests := []struct {
+		name     string
+		input    any
+		hints    []attr.Type
+		expected attr.Value
+	}{
+		{
+			name:     "no hints",
+			input:    map[string]any{"a": "x"},
+			hints:    nil,
+			expected: types.ObjectValueMust(map[string]attr.Type{"a": types.StringType}, map[string]attr.Value{"a": types.StringValue("x")}),
+		},
+		{
+			name:     "maps",
+			input:    map[string]any{"a": "x", "b": "y"},
+			hints:    []attr.Type{stringMap, stringMap},
+			expected: types.MapValueMust(types.StringType, map[string]attr.Value{"a": types.StringValue("x"), "b": types.StringValue("y")}),
+		},
+		{
+			name:     "empty map",
+			input:    map[string]any{},
+			hints:    []attr.Type{stringMap},
+			expected: types.MapValueMust(types.StringType, map[string]attr.Value{}),
+		},
+		{
+			name:     "map merged with empty object",
+			input:    map[string]any{"a": "x"},
+			hints:    []attr.Type{stringMap, types.ObjectType{AttrTypes: map[string]attr.Type{}}, stringMap},
+			expected: types.MapValueMust(types.StringType, map[string]attr.Value{"a": types.StringValue("x")}),
+		},
+		{
+			name:     "nested map merged with empty object",
+			input:    map[string]any{"labels": map[string]any{"a": "x"}},
+			hints:    []attr.Type{types.ObjectType{AttrTypes: map[string]attr.Type{"labels": stringMap}}, types.ObjectType{AttrTypes: map[string]attr.Type{}}},
+			expected: types.ObjectValueMust(map[string]attr.Type{"labels": stringMap}, map[string]attr.Value{"labels": types.MapValueMust(types.StringType, map[string]attr.Value{"a": types.StringValue("x")})}),
+		},
+		{
+			name:  "map merged with object",
+			input: map[string]any{"a": "x"},
+			hints: []attr.Type{stringMap, types.ObjectType{AttrTypes: map[string]attr.Type{"a": types.StringType}}},
+			expected: types.ObjectValueMust(
+				map[string]attr.Type{"a": types.StringType},
+				map[string]attr.Value{"a": types.StringValue("x")},
+			),
+		},
+		{
+			name:  "map with elements of different types",
+			input: map[string]any{"a": "x", "b": true},
+			hints: []attr.Type{stringMap, types.MapType{ElemType: types.BoolType}},
+			expected: types.ObjectValueMust(
+				map[string]attr.Type{"a": types.StringType, "b": types.BoolType},
+				map[string]attr.Value{"a": types.StringValue("x"), "b": types.BoolValue(true)},
+			),
+		},
+		{
+			name:     "map with null element",
+			input:    map[string]any{"a": nil, "b": "y"},
+			hints:    []attr.Type{stringMap},
+			expected: types.MapValueMust(types.StringType, map[string]attr.Value{"a": types.StringNull(), "b": types.StringValue("y")}),
+		},
+		{
+			name:  "null merged from null literal",
+			input: map[string]any{"a": nil, "b": nil},
+			hints: []attr.Type{
+				types.ObjectType{AttrTypes: map[string]attr.Type{"a": stringList, "b": types.NumberType}},
+				types.ObjectType{AttrTypes: map[string]attr.Type{"a": types.DynamicType}},
+			},
+			expected: types.ObjectValueMust(
+				map[string]attr.Type{"a": stringList, "b": types.NumberType},
+				map[string]attr.Value{"a": types.ListNull(types.StringType), "b": types.NumberNull()},
+			),
+		},
+		{
+			name:  "null merged from different types",
+			input: map[string]any{"a": nil, "b": nil},
+			hints: []attr.Type{
+				types.ObjectType{AttrTypes: map[string]attr.Type{"a": types.StringType}},
+				types.ObjectType{AttrTypes: map[string]attr.Type{"a": types.BoolType}},
+			},
+			expected: types.ObjectValueMust(
+				map[string]attr.Type{"a": types.DynamicType, "b": types.DynamicType},
+				map[string]attr.Value{"a": types.DynamicNull(), "b": types.DynamicNull()},
+			),
+		},
+		{
+			name:  "nested collections in object",
+			input: map[string]any{"labels": map[string]any{"a": "x"}, "zones": []any{"a", "b"}},
+			hints: []attr.Type{types.ObjectType{AttrTypes: map[string]attr.Type{"labels": stringMap, "zones": stringList}}},
+			expected: types.ObjectValueMust(
+				map[string]attr.Type{"labels": stringMap, "zones": stringList},
+				map[string]attr.Value{
+					"labels": types.MapValueMust(types.StringType, map[string]attr.Value{"a": types.StringValue("x")}),
+					"zones":  types.ListValueMust(types.StringType, []attr.Value{types.StringValue("a"), types.StringValue("b")}),
+				},
+			),
+		},
+		{
+			name:     "list",
+			input:    []any{"a", "b"},
+			hints:    []attr.Type{stringList},
+			expected: types.ListValueMust(types.StringType, []attr.Value{types.StringValue("a"), types.StringValue("b")}),
+		},
+		{
+			name:     "set",
+			input:    []any{"a", "b"},
+			hints:    []attr.Type{stringSet, stringSet},
+			expected: types.SetValueMust(types.StringType, []attr.Value{types.StringValue("a"), types.StringValue("b")}),
+		},
+		{
+			name:  "set with duplicates",
+			input: []any{"a", "a"},
+			hints: []attr.Type{stringSet},
+			expected: types.TupleValueMust(
+				[]attr.Type{types.StringType, types.StringType},
+				[]attr.Value{types.StringValue("a"), types.StringValue("a")},
+			),
+		},
+		{
+			name:     "set merged with list and tuple",
+			input:    []any{"a", "b"},
+			hints:    []attr.Type{stringList, stringSet, types.TupleType{ElemTypes: []attr.Type{types.StringType}}},
+			expected: types.SetValueMust(types.StringType, []attr.Value{types.StringValue("a"), types.StringValue("b")}),
+		},
+		{
+			name:  "set merged with map",
+			input: []any{"a"},
+			hints: []attr.Type{stringMap, stringSet},
+			expected: types.TupleValueMust(
+				[]attr.Type{types.StringType},
+				[]attr.Value{types.StringValue("a")},
+			),
+		},
+		{
+			name:     "empty set merged with tuple",
+			input:    []any{},
+			hints:    []attr.Type{stringSet, types.TupleType{ElemTypes: []attr.Type{}}},
+			expected: types.SetValueMust(types.StringType, []attr.Value{}),
+		},
+		{
+			name:     "unknown map element",
+			input:    map[string]any{"a": "x", "b": &Unknown{Type: types.StringType}},
+			hints:    []attr.Type{stringMap},
+			expected: types.MapValueMust(types.StringType, map[string]attr.Value{"a": types.StringValue("x"), "b": types.StringUnknown()}),
+		},
+		{
+			name:     "merged unknown set",
+			input:    map[string]any{"tags": NewUnknown()},
+			hints:    []attr.Type{types.ObjectType{AttrTypes: map[string]attr.Type{"tags": stringSet}}, types.MapType{ElemType: stringSet}},
+			expected: types.ObjectValueMust(map[string]attr.Type{"tags": stringSet}, map[string]attr.Value{"tags": types.SetUnknown(types.StringType)}),
+		},
+		{
+			name:  "map in tuple",
+			input: []any{map[string]any{"a": "x"}, "b"},
+			hints: []attr.Type{types.TupleType{ElemTypes: []attr.Type{stringMap, types.StringType}}},
+			expected: types.TupleValueMust(
+				[]attr.Type{stringMap, types.StringType},
+				[]attr.Value{types.MapValueMust(types.StringType, map[string]attr.Value{"a": types.StringValue("x")}), types.StringValue("b")},
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, diags := DecodeTyped(ctx, tt.input, tt.hints)
+			assert.False(t, diags.HasError())
+			assert.True(t, tt.expected.Equal(decoded), "expected %s, got %s", tt.expected, decoded)
+			assert.Equal(t, tt.expected.Type(ctx), decoded.Type(ctx))
+		})
+	}
+}
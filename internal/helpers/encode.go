@@ -6,7 +6,9 @@
 package helpers
 
 import (
+	"context"
 	"fmt"
+	"math/big"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
@@ -19,13 +21,17 @@ func EncodeValue(v attr.Value) (any, error) {
 		return nil, nil
 	}
 
+	if v.IsUnknown() {
+		return &Unknown{Type: v.Type(context.Background())}, nil
+	}
+
 	switch vv := v.(type) {
 	case basetypes.StringValue:
 		return vv.ValueString(), nil
 
 	case basetypes.NumberValue:
-		f, _ := vv.ValueBigFloat().Float64()
-		return f, nil
+		// numbers are kept as big floats, converting them to float64 would corrupt large integers
+		return new(big.Float).Copy(vv.ValueBigFloat()), nil
 
 	case basetypes.BoolValue:
 		return vv.ValueBool(), nil
@@ -33,7 +33,12 @@ func TestEncodeValue(t *testing.T) {
 		{
 			name:     "number value",
 			input:    types.NumberValue(big.NewFloat(123.45)),
-			expected: 123.45,
+			expected: big.NewFloat(123.45),
+		},
+		{
+			name:     "large integer value",
+			input:    types.NumberValue(mustParseNumber("123456789012345678901234567890")),
+			expected: mustParseNumber("123456789012345678901234567890"),
 		},
 		{
 			name:     "bool value",
@@ -69,7 +74,7 @@ func TestEncodeValue(t *testing.T) {
 				})
 				return value
 			}(),
-			expected: []any{"test", 123.45},
+			expected: []any{"test", big.NewFloat(123.45)},
 		},
 		{
 			name: "map value",
@@ -101,6 +106,24 @@ func TestEncodeValue(t *testing.T) {
 			input:    types.DynamicValue(types.StringValue("dynamic")),
 			expected: "dynamic",
 		},
+		{
+			name:     "unknown value",
+			input:    types.StringUnknown(),
+			expected: &Unknown{Type: types.StringType},
+		},
+		{
+			name: "unknown attribute",
+			input: types.ObjectValueMust(
+				map[string]attr.Type{"known": types.StringType, "unknown": types.ListType{ElemType: types.StringType}},
+				map[string]attr.Value{"known": types.StringValue("value"), "unknown": types.ListUnknown(types.StringType)},
+			),
+			expected: map[string]any{"known": "value", "unknown": &Unknown{Type: types.ListType{ElemType: types.StringType}}},
+		},
+		{
+			name:     "unknown dynamic value",
+			input:    types.DynamicUnknown(),
+			expected: &Unknown{Type: types.DynamicType},
+		},
 	}
 
 	for _, tt := range tests {
@@ -111,3 +134,11 @@ func TestEncodeValue(t *testing.T) {
 		})
 	}
 }
+
+func mustParseNumber(s string) *big.Float {
+	f, _, err := big.ParseFloat(s, 10, 512, big.ToNearestEven)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
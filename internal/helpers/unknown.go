@@ -0,0 +1,61 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package helpers
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// Unknown marks a value which isn't known yet during plan, e.g. an attribute of a resource which wasn't created
+// yet. Encoded values contain pointers to it, so unknown values are never equal to each other.
+type Unknown struct {
+	// Type is the type of the unknown value, the dynamic type if it isn't known either.
+	Type attr.Type
+}
+
+// NewUnknown returns a marker of an unknown value of an unknown type.
+func NewUnknown() *Unknown {
+	return &Unknown{Type: types.DynamicType}
+}
+
+// IsUnknown reports whether the encoded value is unknown.
+func IsUnknown(v any) bool {
+	_, ok := v.(*Unknown)
+	return ok
+}
+
+// ContainsUnknown reports whether the encoded value is unknown or contains unknown values.
+func ContainsUnknown(v any) bool {
+	switch vv := v.(type) {
+	case *Unknown:
+		return true
+	case map[string]any:
+		for _, elem := range vv {
+			if ContainsUnknown(elem) {
+				return true
+			}
+		}
+	case []any:
+		for _, elem := range vv {
+			if ContainsUnknown(elem) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// decodeUnknown returns an unknown value of the marker type.
+func decodeUnknown(ctx context.Context, u *Unknown) (attr.Value, diag.Diagnostics) {
+	if _, ok := u.Type.(basetypes.DynamicType); ok || u.Type == nil {
+		return types.DynamicUnknown(), nil
+	}
+	return valueOfType(ctx, u.Type, tftypes.UnknownValue)
+}
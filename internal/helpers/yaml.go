@@ -0,0 +1,733 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package helpers
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// maxAliasedNodes limits the number of nodes decoded through aliases, so documents expanding aliases
+// exponentially are rejected instead of exhausting memory.
+const maxAliasedNodes = 1 << 20
+
+// UnmarshalYAML decodes the first YAML document into an object. Numbers are decoded as big floats without
+// losing precision, except floats which float64 holds exactly, decoded as float64 so they're written back
+// as floats. Plain booleans of YAML 1.1, like yes and off, are decoded as booleans, timestamps are kept as
+// strings, and merge keys are applied. Empty and null documents decode to a nil object.
+func UnmarshalYAML(data []byte) (map[string]any, error) {
+	obj, _, err := UnmarshalYAMLDocument(data)
+	return obj, err
+}
+
+// UnmarshalYAMLDocument decodes the first YAML document like UnmarshalYAML, additionally returning the node
+// of the document, which keeps its comments, key order and styles for MarshalYAMLFrom. The node is nil
+// for empty documents.
+func UnmarshalYAMLDocument(data []byte) (map[string]any, *yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("error unmarshaling YAML: %w", err)
+	}
+	if doc.Kind == 0 || len(doc.Content) == 0 {
+		return nil, nil, nil
+	}
+
+	d := yamlDecoder{resolving: map[*yaml.Node]bool{}}
+	v, err := d.decode(doc.Content[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("error unmarshaling YAML: %w", err)
+	}
+
+	switch vv := v.(type) {
+	case nil:
+		return nil, &doc, nil
+	case map[string]any:
+		return vv, &doc, nil
+	default:
+		return nil, nil, fmt.Errorf("error unmarshaling YAML: document must be a mapping, got: %s", doc.Content[0].ShortTag())
+	}
+}
+
+type yamlDecoder struct {
+	// resolving holds the anchored nodes being decoded through aliases, to detect anchors containing themselves
+	resolving map[*yaml.Node]bool
+	// aliased counts the nodes decoded through aliases
+	aliased int
+}
+
+func (d *yamlDecoder) decode(node *yaml.Node) (any, error) {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return d.decodeAlias(node)
+	case yaml.MappingNode:
+		return d.decodeMapping(node)
+	case yaml.SequenceNode:
+		list := make([]any, len(node.Content))
+		for i, elem := range node.Content {
+			v, err := d.decode(elem)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = v
+		}
+		return list, nil
+	case yaml.ScalarNode:
+		return decodeYAMLScalar(node)
+	}
+	return nil, fmt.Errorf("line %d: unexpected node kind %d", node.Line, node.Kind)
+}
+
+func (d *yamlDecoder) decodeAlias(node *yaml.Node) (any, error) {
+	target := node.Alias
+	if d.resolving[target] {
+		return nil, fmt.Errorf("line %d: anchor %q value contains itself", node.Line, node.Value)
+	}
+
+	if d.aliased++; d.aliased > maxAliasedNodes {
+		return nil, fmt.Errorf("document contains excessive aliasing")
+	}
+
+	d.resolving[target] = true
+	defer delete(d.resolving, target)
+
+	return d.decode(target)
+}
+
+func (d *yamlDecoder) decodeMapping(node *yaml.Node) (any, error) {
+	obj := make(map[string]any, len(node.Content)/2)
+	inherited := map[string]any{}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+
+		if keyNode.Kind == yaml.ScalarNode && keyNode.ShortTag() == "!!merge" {
+			if err := d.decodeMerge(valueNode, inherited); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		key, err := d.decodeKey(keyNode)
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.decode(valueNode)
+		if err != nil {
+			return nil, err
+		}
+		obj[key] = value
+	}
+
+	// keys set explicitly take precedence over merged ones
+	for key, value := range inherited {
+		if _, ok := obj[key]; !ok {
+			obj[key] = value
+		}
+	}
+
+	return obj, nil
+}
+
+// decodeMerge decodes the mappings merged by a merge key into inherited, earlier mappings taking precedence.
+func (d *yamlDecoder) decodeMerge(node *yaml.Node, inherited map[string]any) error {
+	sources := []*yaml.Node{node}
+	if resolveAlias(node).Kind == yaml.SequenceNode {
+		sources = resolveAlias(node).Content
+	}
+
+	for _, source := range sources {
+		if resolveAlias(source).Kind != yaml.MappingNode {
+			return fmt.Errorf("line %d: map merge requires a mapping or a list of mappings", source.Line)
+		}
+
+		v, err := d.decode(source)
+		if err != nil {
+			return err
+		}
+		for key, value := range v.(map[string]any) { //nolint:forcetypeassert
+			if _, ok := inherited[key]; !ok {
+				inherited[key] = value
+			}
+		}
+	}
+
+	return nil
+}
+
+func (d *yamlDecoder) decodeKey(node *yaml.Node) (string, error) {
+	if resolved := resolveAlias(node); resolved.Kind == yaml.ScalarNode {
+		return resolved.Value, nil
+	}
+	return "", fmt.Errorf("line %d: mapping keys must be scalars", node.Line)
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+func decodeYAMLScalar(node *yaml.Node) (any, error) {
+	switch node.ShortTag() {
+	case "!!null":
+		return nil, nil
+
+	case "!!bool":
+		var b bool
+		if err := node.Decode(&b); err != nil {
+			return nil, err
+		}
+		return b, nil
+
+	case "!!int":
+		return parseYAMLNumber(node)
+
+	case "!!float":
+		n, err := parseYAMLNumber(node)
+		if err != nil {
+			return nil, err
+		}
+		return exactFloat64(n), nil
+
+	case "!!str":
+		// plain booleans of YAML 1.1, like yes and off, are read as booleans, as Helm and most YAML 1.1
+		// tools read them
+		if b, ok := yaml11Bools[node.Value]; ok && node.Style == 0 {
+			return b, nil
+		}
+		return node.Value, nil
+
+	default: // timestamps, binary and custom tags are kept as written
+		return node.Value, nil
+	}
+}
+
+// parseYAMLNumber parses the number exactly, as a big float with the precision of Terraform numbers.
+func parseYAMLNumber(node *yaml.Node) (*big.Float, error) {
+	plain := strings.ReplaceAll(node.Value, "_", "")
+
+	if i, ok := new(big.Int).SetString(plain, 0); ok {
+		return new(big.Float).SetPrec(512).SetInt(i), nil
+	}
+	if f, _, err := big.ParseFloat(plain, 10, 512, big.ToNearestEven); err == nil {
+		return f, nil
+	}
+
+	// special values like .inf
+	var f float64
+	if err := node.Decode(&f); err != nil {
+		return nil, err
+	}
+	if math.IsNaN(f) {
+		return nil, fmt.Errorf("line %d: NaN isn't a valid number", node.Line)
+	}
+	return new(big.Float).SetPrec(512).SetFloat64(f), nil
+}
+
+// exactFloat64 returns the number as a float64 if its shortest float64 form has exactly the same value,
+// keeping the number a big float otherwise. Integral big floats are written as integers, so floats like
+// 1.0 and 1e3 are kept as float64 to be written back as floats.
+func exactFloat64(n *big.Float) any {
+	if n.IsInf() {
+		return n
+	}
+	f, _ := n.Float64()
+	shortest, _, err := big.ParseFloat(strconv.FormatFloat(f, 'g', -1, 64), 10, n.Prec(), big.ToNearestEven)
+	if err != nil || shortest.Cmp(n) != 0 {
+		return n
+	}
+	return f
+}
+
+// yaml11Bools are the strings YAML 1.1 resolves as booleans, but YAML 1.2 doesn't, with their values.
+var yaml11Bools = map[string]bool{
+	"y": true, "Y": true, "yes": true, "Yes": true, "YES": true,
+	"n": false, "N": false, "no": false, "No": false, "NO": false,
+	"on": true, "On": true, "ON": true,
+	"off": false, "Off": false, "OFF": false,
+}
+
+// YAMLFormat controls the layout of documents written by MarshalYAML. The zero value indents nested
+// values by two spaces, writes lists in block style and quotes strings only when needed. The fields are
+// tagged with the names of the settings of the yaml option of yaml_deep_merge.
+type YAMLFormat struct {
+	// Indent is the number of spaces indenting nested values, 2 if zero.
+	Indent int `mapstructure:"indent"`
+	// IndentSequences indents lists nested in mappings, instead of aligning their dashes with the keys.
+	IndentSequences bool `mapstructure:"indent_sequences"`
+	// LineWidth is the width to which long strings are folded at spaces, zero for no folding.
+	LineWidth int `mapstructure:"line_width"`
+	// FlowLists is the maximum number of elements of lists of scalars written in flow style, like [a, b].
+	FlowLists int `mapstructure:"flow_lists"`
+	// QuoteStyle selects the quotes of quoted strings, YAMLSingleQuoted or YAMLDoubleQuoted. If empty,
+	// the quotes needing less escaping are used.
+	QuoteStyle string `mapstructure:"quote_style"`
+	// QuoteStrings quotes all single-line strings, not only the ones which would be read as other values.
+	QuoteStrings bool `mapstructure:"quote_strings"`
+	// DocumentStart starts the document with the --- marker.
+	DocumentStart bool `mapstructure:"document_start"`
+	// EmptyObject writes empty objects as {}, instead of an empty document.
+	EmptyObject bool `mapstructure:"empty_object"`
+}
+
+const (
+	YAMLSingleQuoted = "single"
+	YAMLDoubleQuoted = "double"
+)
+
+// maxSimpleKeyLength is the maximum length of keys written before the colon, longer keys are written
+// as explicit keys.
+const maxSimpleKeyLength = 1024
+
+// MarshalYAML encodes the object as a YAML document in the format, with keys sorted and numbers written
+// exactly. Empty objects are encoded as an empty document unless the format sets EmptyObject.
+func MarshalYAML(obj map[string]any, format YAMLFormat) ([]byte, error) {
+	return MarshalYAMLFrom(obj, nil, format)
+}
+
+// yamlWriter writes the block structure of documents, leaving the scalars and flow collections
+// to the YAML encoder.
+type yamlWriter struct {
+	YAMLFormat
+	buf bytes.Buffer
+	// kept is set after a block scalar keeping its trailing line breaks, which would take in empty lines
+	kept bool
+}
+
+// value writes the node as the value following the line, which ends with the key and colon, or is empty
+// for the document. col is the indentation of the key. The line comment is written at the end of the line,
+// or of the scalar.
+func (w *yamlWriter) value(node *yaml.Node, col int, line, comment string) error {
+	if line != "" {
+		if w.isBlock(node) {
+			w.line(withComment(line, comment))
+			if node.Kind == yaml.MappingNode || w.IndentSequences {
+				col += w.Indent
+			}
+		} else {
+			line += " "
+		}
+	}
+
+	switch {
+	case w.isBlock(node) && node.Kind == yaml.MappingNode:
+		w.comment(node.HeadComment, col)
+		return w.mapping(node, col, indent(col))
+	case w.isBlock(node):
+		w.comment(node.HeadComment, col)
+		return w.sequence(node, col, indent(col))
+	default:
+		return w.scalar(node, col, line, comment)
+	}
+}
+
+// mapping writes the pairs of the mapping indented by col, the first one following the prefix.
+func (w *yamlWriter) mapping(node *yaml.Node, col int, prefix string) error {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		if i > 0 {
+			prefix = indent(col)
+		}
+
+		key, err := w.key(keyNode)
+		if err != nil {
+			return err
+		}
+
+		w.comment(keyNode.HeadComment, prefixIndent(prefix))
+		if len(key) > maxSimpleKeyLength {
+			w.line(prefix + "? " + key)
+			prefix = indent(col)
+			key = ""
+		}
+
+		comment := keyNode.LineComment
+		if comment == "" {
+			comment = valueNode.LineComment
+		}
+		if err := w.value(valueNode, col, prefix+key+":", comment); err != nil {
+			return err
+		}
+		w.comment(valueNode.FootComment, col)
+		w.comment(keyNode.FootComment, col)
+	}
+
+	return nil
+}
+
+// sequence writes the elements of the sequence with dashes indented by col, the first one following the prefix.
+func (w *yamlWriter) sequence(node *yaml.Node, col int, prefix string) error {
+	for i, elem := range node.Content {
+		if i > 0 {
+			prefix = indent(col)
+		}
+		w.comment(elem.HeadComment, prefixIndent(prefix))
+		prefix += "- "
+
+		var err error
+		switch {
+		case w.isBlock(elem) && elem.Kind == yaml.MappingNode:
+			err = w.mapping(elem, col+2, prefix)
+		case w.isBlock(elem):
+			err = w.sequence(elem, col+2, prefix)
+		default:
+			err = w.scalar(elem, col, prefix, elem.LineComment)
+		}
+		if err != nil {
+			return err
+		}
+		w.comment(elem.FootComment, col)
+	}
+
+	return nil
+}
+
+// scalar writes the scalar, empty collection or flow collection following the line, folding long strings.
+// col is the indentation of the enclosing key or dash, continuation lines are indented further. The line
+// comment is written after the header of block scalars, or at the end of other scalars.
+func (w *yamlWriter) scalar(node *yaml.Node, col int, line, comment string) error {
+	var text string
+	var err error
+	if node.Kind != yaml.ScalarNode && len(node.Content) > 0 {
+		text, err = w.flow(node)
+	} else {
+		text, err = w.render(node, false, w.QuoteStrings)
+	}
+	if err != nil {
+		return err
+	}
+
+	// multi-line scalars are rendered as documents, so their lines are indented relative to the key
+	if strings.ContainsAny(text, yamlBreaks) {
+		lines := strings.Split(indentBreaks(text, col), "\n")
+		block := blockScalarHeader.MatchString(lines[0])
+		if block {
+			lines[0] = withComment(lines[0], comment)
+		} else {
+			lines[len(lines)-1] = withComment(lines[len(lines)-1], comment)
+		}
+
+		w.line(line + lines[0])
+		for _, l := range lines[1:] {
+			w.line(l)
+		}
+		w.kept = block && strings.Contains(lines[0], "+")
+		return nil
+	}
+
+	if w.LineWidth > 0 && node.Kind == yaml.ScalarNode {
+		w.fold(line, text, col+w.Indent, comment)
+		return nil
+	}
+
+	w.line(withComment(line+text, comment))
+	return nil
+}
+
+// blockScalarHeader matches the first line of literal and folded scalars, which may be tagged.
+var blockScalarHeader = regexp.MustCompile(`^(!\S* )?[|>][1-9]?[+-]?$`)
+
+// flow renders the sequence or mapping of scalars in flow style.
+func (w *yamlWriter) flow(node *yaml.Node) (string, error) {
+	if node.Kind == yaml.SequenceNode {
+		elems := make([]string, len(node.Content))
+		for i, elem := range node.Content {
+			text, err := w.render(elem, true, w.QuoteStrings)
+			if err != nil {
+				return "", err
+			}
+			elems[i] = text
+		}
+		return "[" + strings.Join(elems, ", ") + "]", nil
+	}
+
+	pairs := make([]string, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, err := w.render(node.Content[i], true, false)
+		if err != nil {
+			return "", err
+		}
+		value, err := w.render(node.Content[i+1], true, w.QuoteStrings)
+		if err != nil {
+			return "", err
+		}
+		pairs = append(pairs, key+": "+value)
+	}
+	return "{" + strings.Join(pairs, ", ") + "}", nil
+}
+
+// yamlBreaks are the characters YAML reads as line breaks.
+const yamlBreaks = "\n\r\u0085\u2028\u2029"
+
+// key renders the mapping key, quoting keys spanning multiple lines.
+func (w *yamlWriter) key(node *yaml.Node) (string, error) {
+	if node.Kind != yaml.ScalarNode {
+		return "", fmt.Errorf("line %d: mapping keys must be scalars", node.Line)
+	}
+
+	text, err := w.render(node, false, false)
+	if err != nil || !strings.ContainsAny(text, yamlBreaks) {
+		return text, err
+	}
+
+	quoted := *node
+	quoted.Style = yaml.DoubleQuotedStyle
+	return encodeYAMLScalar(&quoted, w.Indent, false)
+}
+
+// render renders the scalar or empty collection, quoting strings with the quotes of the format, when
+// needed or if quote is set. Elements of flow sequences are rendered in flow context.
+func (w *yamlWriter) render(node *yaml.Node, flow, quote bool) (string, error) {
+	if node.Kind != yaml.ScalarNode || node.Style != 0 || node.ShortTag() != "!!str" || strings.Contains(node.Value, "\n") {
+		return encodeYAMLScalar(node, w.Indent, flow)
+	}
+
+	quotes := yaml.DoubleQuotedStyle
+	if w.QuoteStyle == YAMLSingleQuoted {
+		quotes = yaml.SingleQuotedStyle
+	}
+
+	styled := *node
+	if _, ok := yaml11Bools[node.Value]; quote || ok {
+		// YAML 1.1 decoders, including Terraform yamldecode, read the booleans of YAML 1.1 unquoted
+		styled.Style = quotes
+	}
+
+	text, err := encodeYAMLScalar(&styled, w.Indent, flow)
+	if err != nil || w.QuoteStyle == "" || !isQuoted(text) || text[0] == quoteChar(quotes) {
+		return text, err
+	}
+
+	// quoted when needed, but with the other quotes
+	styled.Style = quotes
+	return encodeYAMLScalar(&styled, w.Indent, flow)
+}
+
+// fold writes the line followed by the scalar, breaking the scalar at single spaces so lines fit
+// the line width where possible. Continuation lines are indented by col, and the last one ends
+// with the comment.
+func (w *yamlWriter) fold(line, text string, col int, comment string) {
+	words := splitFoldable(text)
+	width := utf8.RuneCountInString(line) + utf8.RuneCountInString(words[0])
+	line += words[0]
+
+	for _, word := range words[1:] {
+		n := utf8.RuneCountInString(word)
+		if width+1+n > w.LineWidth {
+			w.line(line)
+			line, width = indent(col)+word, col+n
+			continue
+		}
+		line += " " + word
+		width += 1 + n
+	}
+
+	w.line(withComment(line, comment))
+}
+
+func (w *yamlWriter) line(s string) {
+	if s == "" && w.kept {
+		return
+	}
+	w.kept = false
+	w.buf.WriteString(s)
+	w.buf.WriteByte('\n')
+}
+
+// comment writes the lines of the head or foot comment indented by col.
+func (w *yamlWriter) comment(text string, col int) {
+	if text == "" {
+		return
+	}
+	for _, l := range strings.Split(text, "\n") {
+		if l != "" {
+			l = indent(col) + l
+		}
+		w.line(l)
+	}
+}
+
+func withComment(line, comment string) string {
+	if comment == "" {
+		return line
+	}
+	return line + " " + comment
+}
+
+// isBlock reports whether the node is written in block style, on lines following its key or dash.
+func (w *yamlWriter) isBlock(node *yaml.Node) bool {
+	switch node.Kind {
+	case yaml.MappingNode, yaml.SequenceNode:
+		return len(node.Content) > 0 && !w.isFlow(node)
+	}
+	return false
+}
+
+// isFlow reports whether the collection is written in flow style, because it is a short sequence or has
+// the flow style set. Only collections of single-line scalars are written in flow style.
+func (w *yamlWriter) isFlow(node *yaml.Node) bool {
+	if node.Style&yaml.FlowStyle == 0 && (node.Kind != yaml.SequenceNode || len(node.Content) > w.FlowLists) {
+		return false
+	}
+	for _, elem := range node.Content {
+		if elem.Kind != yaml.ScalarNode || strings.ContainsAny(elem.Value, yamlBreaks) {
+			return false
+		}
+	}
+	return true
+}
+
+// indentBreaks indents the lines following the line breaks of the rendered scalar by col, keeping empty
+// lines empty.
+func indentBreaks(text string, col int) string {
+	var sb strings.Builder
+	runes := []rune(text)
+	for i, r := range runes {
+		sb.WriteRune(r)
+		if strings.ContainsRune(yamlBreaks, r) && i+1 < len(runes) && !strings.ContainsRune(yamlBreaks, runes[i+1]) {
+			sb.WriteString(indent(col))
+		}
+	}
+	return sb.String()
+}
+
+// splitFoldable splits the rendered scalar at the spaces a YAML decoder folds back, which are single
+// spaces between other characters.
+func splitFoldable(text string) []string {
+	words := []string{}
+	start := 0
+	for i := 1; i+1 < len(text); i++ {
+		if text[i] == ' ' && text[i-1] != ' ' && text[i-1] != '\\' && text[i+1] != ' ' {
+			words = append(words, text[start:i])
+			start = i + 1
+		}
+	}
+	return append(words, text[start:])
+}
+
+// encodeYAMLScalar renders the node as a document, without comments and the final line break. In flow context,
+// the node is rendered as an element of a flow sequence.
+func encodeYAMLScalar(node *yaml.Node, indent int, flow bool) (string, error) {
+	// comments are written around the rendered scalar
+	uncommented := *node
+	uncommented.HeadComment, uncommented.LineComment, uncommented.FootComment = "", "", ""
+	node = &uncommented
+
+	if flow {
+		node = &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle, Content: []*yaml.Node{node}}
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(indent)
+	if err := enc.Encode(node); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+
+	text := strings.TrimSuffix(buf.String(), "\n")
+	if flow {
+		text = strings.TrimSuffix(strings.TrimPrefix(text, "["), "]")
+	}
+	return text, nil
+}
+
+func isQuoted(text string) bool {
+	return strings.HasPrefix(text, "'") || strings.HasPrefix(text, `"`)
+}
+
+func quoteChar(style yaml.Style) byte {
+	if style == yaml.SingleQuotedStyle {
+		return '\''
+	}
+	return '"'
+}
+
+func indent(n int) string {
+	return strings.Repeat(" ", n)
+}
+
+// prefixIndent returns the indentation of the line prefix, aligning the comments of the first pair or element
+// of sequence elements with their dash.
+func prefixIndent(prefix string) int {
+	return len(prefix) - len(strings.TrimLeft(prefix, " "))
+}
+
+func encodeYAMLNode(v any) (*yaml.Node, error) {
+	switch vv := v.(type) {
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(vv)}, nil
+
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: vv}, nil
+
+	case float64:
+		if math.IsInf(vv, 0) {
+			return encodeYAMLNode(big.NewFloat(vv))
+		}
+		// floats are written in a float form, even when integral
+		value := strconv.FormatFloat(vv, 'g', -1, 64)
+		if !strings.ContainsAny(value, ".e") {
+			value += ".0"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: value}, nil
+
+	case *big.Float:
+		switch {
+		case vv.IsInf() && vv.Sign() > 0:
+			return &yaml.Node{Kind: yaml.ScalarNode, Value: ".inf"}, nil
+		case vv.IsInf():
+			return &yaml.Node{Kind: yaml.ScalarNode, Value: "-.inf"}, nil
+		case vv.IsInt():
+			return &yaml.Node{Kind: yaml.ScalarNode, Value: vv.Text('f', 0)}, nil
+		default:
+			return &yaml.Node{Kind: yaml.ScalarNode, Value: vv.Text('g', -1)}, nil
+		}
+
+	case []any:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, elem := range vv {
+			elemNode, err := encodeYAMLNode(elem)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, elemNode)
+		}
+		return node, nil
+
+	case map[string]any:
+		keys := make([]string, 0, len(vv))
+		for key := range vv {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, key := range keys {
+			valueNode, err := encodeYAMLNode(vv[key])
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, valueNode)
+		}
+		return node, nil
+	}
+
+	return nil, fmt.Errorf("tried to encode unsupported type: %T: %v", v, v)
+}
@@ -0,0 +1,353 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package helpers
+
+import (
+	"cmp"
+	"math/big"
+	"slices"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// MarshalYAMLFrom encodes the object like MarshalYAML, laid out like the documents it was merged from,
+// as returned by UnmarshalYAMLDocument. Keys are ordered as in the first document having them, followed
+// by keys missing in all documents in sorted order. Comments of keys and elements are kept from the first
+// document commenting them, and scalars keep the style of the last document setting them to the same value.
+func MarshalYAMLFrom(obj map[string]any, docs []*yaml.Node, format YAMLFormat) ([]byte, error) {
+	if len(obj) == 0 && !format.EmptyObject {
+		return []byte{}, nil
+	}
+
+	node, err := encodeYAMLNode(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := []*yaml.Node{}
+	for _, doc := range docs {
+		if doc != nil && len(doc.Content) > 0 {
+			sources = append(sources, resolveAlias(doc.Content[0]))
+		}
+	}
+	l := yamlLayout{values: map[*yaml.Node]any{}}
+	l.apply(node, obj, sources)
+
+	if format.Indent == 0 {
+		format.Indent = 2
+	}
+	w := yamlWriter{YAMLFormat: format}
+	if format.DocumentStart {
+		w.buf.WriteString("---\n")
+	}
+	if comment := firstComment(docs, func(doc *yaml.Node) string { return doc.HeadComment }); comment != "" {
+		w.comment(comment, 0)
+		w.line("")
+	}
+	if err := w.value(node, 0, "", ""); err != nil {
+		return nil, err
+	}
+	w.comment(node.FootComment, 0)
+	if comment := firstComment(docs, func(doc *yaml.Node) string { return doc.FootComment }); comment != "" {
+		w.line("")
+		w.comment(comment, 0)
+	}
+
+	return w.buf.Bytes(), nil
+}
+
+// yamlLayout lays out encoded nodes like the source nodes their values were merged from.
+type yamlLayout struct {
+	// values caches the decoded values of source nodes
+	values map[*yaml.Node]any
+}
+
+// apply lays out the node of the value like the sources, the nodes at the same path in the merged
+// documents having it, in the order of the documents.
+func (l *yamlLayout) apply(node *yaml.Node, value any, sources []*yaml.Node) {
+	if len(sources) == 0 {
+		return
+	}
+	copyComments(node, sources)
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		l.applyMapping(node, value.(map[string]any), sources) //nolint:forcetypeassert
+	case yaml.SequenceNode:
+		l.applySequence(node, value.([]any), sources) //nolint:forcetypeassert
+	case yaml.ScalarNode:
+		// the scalar is written as in the last document setting the value, including its tag, unless it's
+		// written with the tag of another type, like yes read as a YAML 1.1 boolean, keeping only its style
+		tag := scalarTag(node)
+		for _, source := range sources {
+			if source.Kind != yaml.ScalarNode || !equalYAMLValues(l.value(source), value) {
+				continue
+			}
+			if scalarTag(source) == tag {
+				node.Tag, node.Value, node.Style = source.Tag, source.Value, source.Style
+			} else {
+				node.Style = source.Style
+			}
+		}
+		if node.Style == yaml.FoldedStyle && !foldsBack(node) {
+			node.Style = yaml.LiteralStyle
+		}
+	}
+}
+
+func (l *yamlLayout) applyMapping(node *yaml.Node, obj map[string]any, sources []*yaml.Node) {
+	mappings := ofKind(sources, yaml.MappingNode)
+	if len(mappings) == 0 {
+		return
+	}
+	node.Style = mappings[0].Style & yaml.FlowStyle
+
+	pairs := make([]map[string][2]*yaml.Node, len(mappings))
+	order := []string{}
+	added := map[string]bool{}
+	for i, mapping := range mappings {
+		pairs[i] = map[string][2]*yaml.Node{}
+		for _, pair := range yamlPairs(mapping) {
+			key := pair[0].Value
+			pairs[i][key] = pair
+			if _, ok := obj[key]; ok && !added[key] {
+				added[key] = true
+				order = append(order, key)
+			}
+		}
+	}
+
+	// keys not in the documents, like keys of objects replacing other values, follow in sorted order
+	index := make(map[string]int, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		index[key] = i
+		if !added[key] {
+			order = append(order, key)
+		}
+	}
+
+	content := make([]*yaml.Node, 0, len(node.Content))
+	for _, key := range order {
+		keyNode, valueNode := node.Content[index[key]], node.Content[index[key]+1]
+
+		var keySources, valueSources []*yaml.Node
+		for i := range mappings {
+			if pair, ok := pairs[i][key]; ok {
+				keySources = append(keySources, pair[0])
+				valueSources = append(valueSources, resolveAlias(pair[1]))
+			}
+		}
+		if len(keySources) > 0 {
+			copyComments(keyNode, keySources)
+			keyNode.Tag, keyNode.Style = keySources[0].Tag, keySources[0].Style
+		}
+
+		l.apply(valueNode, obj[key], valueSources)
+		content = append(content, keyNode, valueNode)
+	}
+
+	node.Content = content
+}
+
+func (l *yamlLayout) applySequence(node *yaml.Node, list []any, sources []*yaml.Node) {
+	sequences := ofKind(sources, yaml.SequenceNode)
+	if len(sequences) == 0 {
+		return
+	}
+	node.Style = sequences[0].Style & yaml.FlowStyle
+
+	used := make([]map[int]bool, len(sequences))
+	for i := range used {
+		used[i] = map[int]bool{}
+	}
+
+	for i, elem := range node.Content {
+		var elemSources []*yaml.Node
+		for s, sequence := range sequences {
+			if j := l.match(sequence, list[i], used[s]); j >= 0 {
+				used[s][j] = true
+				elemSources = append(elemSources, resolveAlias(sequence.Content[j]))
+			}
+		}
+		l.apply(elem, list[i], elemSources)
+	}
+}
+
+// match returns the index of the unused element of the sequence the value was merged from, or -1 if there
+// is none. Elements equal to the value match first, objects then match the objects sharing the most fields
+// with them.
+func (l *yamlLayout) match(sequence *yaml.Node, value any, used map[int]bool) int {
+	for j, elem := range sequence.Content {
+		if !used[j] && equalYAMLValues(l.value(resolveAlias(elem)), value) {
+			return j
+		}
+	}
+
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return -1
+	}
+
+	best, bestScore := -1, 0
+	for j, elem := range sequence.Content {
+		source, ok := l.value(resolveAlias(elem)).(map[string]any)
+		if used[j] || !ok {
+			continue
+		}
+
+		score := 0
+		for key, v := range obj {
+			if w, ok := source[key]; ok && equalYAMLValues(w, v) {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestScore = j, score
+		}
+	}
+
+	return best
+}
+
+// value returns the decoded value of the source node.
+func (l *yamlLayout) value(node *yaml.Node) any {
+	if v, ok := l.values[node]; ok {
+		return v
+	}
+
+	// the documents were already decoded, so decoding their nodes doesn't fail
+	d := yamlDecoder{resolving: map[*yaml.Node]bool{}}
+	v, _ := d.decode(node)
+	l.values[node] = v
+	return v
+}
+
+// yamlPairs returns the key and value nodes of the mapping in order, with the pairs of mappings merged
+// by merge keys in place of the merge keys, unless their keys are set explicitly.
+func yamlPairs(node *yaml.Node) [][2]*yaml.Node {
+	explicit := map[string]bool{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		explicit[resolveAlias(node.Content[i]).Value] = true
+	}
+
+	pairs := [][2]*yaml.Node{}
+	seen := map[string]bool{}
+	add := func(pair [2]*yaml.Node) {
+		if !seen[pair[0].Value] {
+			seen[pair[0].Value] = true
+			pairs = append(pairs, pair)
+		}
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		if keyNode.Kind != yaml.ScalarNode || keyNode.ShortTag() != "!!merge" {
+			add([2]*yaml.Node{resolveAlias(keyNode), valueNode})
+			continue
+		}
+
+		merged := []*yaml.Node{resolveAlias(valueNode)}
+		if merged[0].Kind == yaml.SequenceNode {
+			merged = merged[0].Content
+		}
+		for _, source := range merged {
+			if source = resolveAlias(source); source.Kind != yaml.MappingNode {
+				continue
+			}
+			for _, pair := range yamlPairs(source) {
+				if !explicit[pair[0].Value] {
+					add(pair)
+				}
+			}
+		}
+	}
+
+	return pairs
+}
+
+// foldsBack reports whether the folded scalar is decoded back to its value, which isn't the case for some
+// scalars ending with empty lines.
+func foldsBack(node *yaml.Node) bool {
+	data, err := yaml.Marshal(&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: node.Value, Style: yaml.FoldedStyle})
+	if err != nil {
+		return false
+	}
+
+	var value string
+	return yaml.Unmarshal(data, &value) == nil && value == node.Value
+}
+
+func ofKind(nodes []*yaml.Node, kind yaml.Kind) []*yaml.Node {
+	matching := []*yaml.Node{}
+	for _, node := range nodes {
+		if node.Kind == kind {
+			matching = append(matching, node)
+		}
+	}
+	return matching
+}
+
+// copyComments sets each comment of the node to the first comment of the sources.
+func copyComments(node *yaml.Node, sources []*yaml.Node) {
+	for _, source := range slices.Backward(sources) {
+		node.HeadComment = cmp.Or(source.HeadComment, node.HeadComment)
+		node.LineComment = cmp.Or(source.LineComment, node.LineComment)
+		node.FootComment = cmp.Or(source.FootComment, node.FootComment)
+	}
+}
+
+func firstComment(docs []*yaml.Node, comment func(*yaml.Node) string) string {
+	for _, doc := range docs {
+		if doc != nil && comment(doc) != "" {
+			return comment(doc)
+		}
+	}
+	return ""
+}
+
+// scalarTag returns the resolved tag of the scalar, tags of scalars kept as strings, like timestamps,
+// being !!str.
+func scalarTag(node *yaml.Node) string {
+	switch tag := node.ShortTag(); tag {
+	case "!!null", "!!bool", "!!int", "!!float":
+		return tag
+	default:
+		return "!!str"
+	}
+}
+
+// equalYAMLValues reports whether the decoded values are deeply equal, comparing numbers by their values.
+func equalYAMLValues(a, b any) bool {
+	switch av := a.(type) {
+	case *big.Float:
+		bv, ok := b.(*big.Float)
+		return ok && av.Cmp(bv) == 0
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !equalYAMLValues(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for key, v := range av {
+			if w, ok := bv[key]; !ok || !equalYAMLValues(v, w) {
+				return false
+			}
+		}
+		return true
+	case nil, bool, string, float64:
+		return a == b
+	}
+	return false
+}
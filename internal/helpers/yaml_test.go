@@ -0,0 +1,460 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package helpers
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.yaml.in/yaml/v3"
+)
+
+func TestUnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected map[string]any
+		err      string
+	}{
+		{
+			name:     "empty document",
+			input:    "",
+			expected: nil,
+		},
+		{
+			name:     "null document",
+			input:    "~",
+			expected: nil,
+		},
+		{
+			name:  "scalars",
+			input: "s: text\nb: true\nn: null\nq: \"123\"\nt: 2001-12-14\n",
+			expected: map[string]any{
+				"s": "text",
+				"b": true,
+				"n": nil,
+				"q": "123",
+				"t": "2001-12-14",
+			},
+		},
+		{
+			name:  "YAML 1.1 booleans",
+			input: "yes: yes\noff: Off\ny: y\nN: N\nquoted: \"on\"\ntagged: !!str no\n",
+			expected: map[string]any{
+				"yes":    true,
+				"off":    false,
+				"y":      true,
+				"N":      false,
+				"quoted": "on",
+				"tagged": "no",
+			},
+		},
+		{
+			name:  "numbers",
+			input: "int: 42\nbig: 123456789012345678901234567890\naccount: 123456789012\nfloat: 1.5\nhex: 0x1F\nunderscores: 1_000\ninf: .inf\n",
+			expected: map[string]any{
+				"int":         mustParseNumber("42"),
+				"big":         mustParseNumber("123456789012345678901234567890"),
+				"account":     mustParseNumber("123456789012"),
+				"float":       1.5,
+				"hex":         mustParseNumber("31"),
+				"underscores": mustParseNumber("1000"),
+				"inf":         new(big.Float).SetPrec(512).SetInf(false),
+			},
+		},
+		{
+			name:  "anchors and merge keys",
+			input: "base: &base\n  a: 1\n  b: 2\nderived:\n  <<: *base\n  b: 3\nlist: [*base]\n",
+			expected: map[string]any{
+				"base":    map[string]any{"a": mustParseNumber("1"), "b": mustParseNumber("2")},
+				"derived": map[string]any{"a": mustParseNumber("1"), "b": mustParseNumber("3")},
+				"list":    []any{map[string]any{"a": mustParseNumber("1"), "b": mustParseNumber("2")}},
+			},
+		},
+		{
+			name:  "non-string keys",
+			input: "1: a\ntrue: b\n",
+			expected: map[string]any{
+				"1":    "a",
+				"true": "b",
+			},
+		},
+		{
+			name:  "not a mapping",
+			input: "a",
+			err:   "error unmarshaling YAML: document must be a mapping, got: !!str",
+		},
+		{
+			name:  "syntax error",
+			input: "a: [",
+			err:   "error unmarshaling YAML: yaml: line 1: did not find expected node content",
+		},
+		{
+			name:  "NaN",
+			input: "a: .nan",
+			err:   "error unmarshaling YAML: line 1: NaN isn't a valid number",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj, err := UnmarshalYAML([]byte(tt.input))
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, len(tt.expected), len(obj))
+			for key, expected := range tt.expected {
+				assert.True(t, equalNumbers(expected, obj[key]), "%s: expected %v, got %v", key, expected, obj[key])
+			}
+		})
+	}
+}
+
+func TestMarshalYAML(t *testing.T) {
+	obj := map[string]any{
+		"b":      "text",
+		"c":      []any{"yes", "off"},
+		"a":      []any{mustParseNumber("123456789012345678901234567890"), 1.5, "true", nil},
+		"nested": map[string]any{"multiline": "a\nb\n", "empty": map[string]any{}},
+	}
+
+	out, err := MarshalYAML(obj, YAMLFormat{})
+	assert.NoError(t, err)
+	assert.Equal(t, `a:
+- 123456789012345678901234567890
+- 1.5
+- "true"
+- null
+b: text
+c:
+- "yes"
+- "off"
+nested:
+  empty: {}
+  multiline: |
+    a
+    b
+`, string(out))
+
+	decoded, err := UnmarshalYAML(out)
+	assert.NoError(t, err)
+	assert.True(t, equalNumbers(obj["a"], decoded["a"]))
+}
+
+func TestMarshalYAMLFloats(t *testing.T) {
+	obj, err := UnmarshalYAML([]byte("one: 1.0\nthousand: 1e3\nhalf: 0.5\nlarge: 1e30\nprecise: 1.00000000000000000000000000001\nint: 1\n"))
+	assert.NoError(t, err)
+
+	out, err := MarshalYAML(obj, YAMLFormat{})
+	assert.NoError(t, err)
+	assert.Equal(t, `half: 0.5
+int: 1
+large: 1e+30
+one: 1.0
+precise: 1.00000000000000000000000000001
+thousand: 1000.0
+`, string(out))
+
+	decoded, err := UnmarshalYAML(out)
+	assert.NoError(t, err)
+	assert.True(t, equalNumbers(obj, decoded))
+}
+
+func TestMarshalYAMLFormat(t *testing.T) {
+	obj := map[string]any{
+		"name":        "web",
+		"description": "a long description which doesn't fit on a single line of the document",
+		"ports":       []any{mustParseNumber("80"), mustParseNumber("443")},
+		"hosts":       []any{map[string]any{"host": "example.com", "paths": []any{"/"}}},
+		"enabled":     "yes",
+		"script":      "set -e\nrun\n",
+		"empty":       []any{},
+	}
+
+	tests := []struct {
+		name     string
+		format   YAMLFormat
+		expected string
+	}{
+		{
+			name:   "default",
+			format: YAMLFormat{},
+			expected: `description: a long description which doesn't fit on a single line of the document
+empty: []
+enabled: "yes"
+hosts:
+- host: example.com
+  paths:
+  - /
+name: web
+ports:
+- 80
+- 443
+script: |
+  set -e
+  run
+`,
+		},
+		{
+			name:   "indent",
+			format: YAMLFormat{Indent: 4, IndentSequences: true},
+			expected: `description: a long description which doesn't fit on a single line of the document
+empty: []
+enabled: "yes"
+hosts:
+    - host: example.com
+      paths:
+          - /
+name: web
+ports:
+    - 80
+    - 443
+script: |
+    set -e
+    run
+`,
+		},
+		{
+			name:   "line width",
+			format: YAMLFormat{LineWidth: 40},
+			expected: `description: a long description which
+  doesn't fit on a single line of the
+  document
+empty: []
+enabled: "yes"
+hosts:
+- host: example.com
+  paths:
+  - /
+name: web
+ports:
+- 80
+- 443
+script: |
+  set -e
+  run
+`,
+		},
+		{
+			name:   "flow lists",
+			format: YAMLFormat{FlowLists: 2},
+			expected: `description: a long description which doesn't fit on a single line of the document
+empty: []
+enabled: "yes"
+hosts:
+- host: example.com
+  paths: [/]
+name: web
+ports: [80, 443]
+script: |
+  set -e
+  run
+`,
+		},
+		{
+			name:   "quotes",
+			format: YAMLFormat{QuoteStyle: YAMLSingleQuoted, QuoteStrings: true, DocumentStart: true, LineWidth: 60},
+			expected: `---
+description: 'a long description which doesn''t fit on a
+  single line of the document'
+empty: []
+enabled: 'yes'
+hosts:
+- host: 'example.com'
+  paths:
+  - '/'
+name: 'web'
+ports:
+- 80
+- 443
+script: |
+  set -e
+  run
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := MarshalYAML(obj, tt.format)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(out))
+
+			decoded, err := UnmarshalYAML(out)
+			assert.NoError(t, err)
+			assert.True(t, equalNumbers(obj, decoded), "decoded: %v", decoded)
+		})
+	}
+}
+
+func TestMarshalYAMLQuoteStyle(t *testing.T) {
+	obj := map[string]any{"a": "true", "b": "x: y", "c": "it's", "d": "plain", "key: x": "tab\t"}
+
+	out, err := MarshalYAML(obj, YAMLFormat{QuoteStyle: YAMLDoubleQuoted})
+	assert.NoError(t, err)
+	assert.Equal(t, `a: "true"
+b: "x: y"
+c: it's
+d: plain
+"key: x": "tab\t"
+`, string(out))
+
+	out, err = MarshalYAML(obj, YAMLFormat{QuoteStyle: YAMLSingleQuoted})
+	assert.NoError(t, err)
+	assert.Equal(t, `a: 'true'
+b: 'x: y'
+c: it's
+d: plain
+'key: x': "tab\t"
+`, string(out))
+}
+
+func TestMarshalYAMLEmpty(t *testing.T) {
+	out, err := MarshalYAML(map[string]any{}, YAMLFormat{DocumentStart: true})
+	assert.NoError(t, err)
+	assert.Empty(t, out)
+
+	out, err = MarshalYAML(nil, YAMLFormat{EmptyObject: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "{}\n", string(out))
+}
+
+func TestMarshalYAMLFrom(t *testing.T) {
+	base := `# Chart values
+
+# Number of pods
+replicas: 1 # scaled by HPA
+defaults: &defaults
+  pullPolicy: IfNotPresent
+image:
+  <<: *defaults
+  repository: nginx
+  tag: '1.25' # pinned
+ports: [80]
+env:
+  # set for all environments
+  - name: LOG_LEVEL
+    value: info
+script: |
+  echo start
+`
+	overlay := `image:
+  tag: "1.26"
+# extra labels
+labels:
+  team: web # owner
+  app: shop
+env:
+  - name: LOG_LEVEL
+    value: debug
+  - name: PORT
+    value: "8080"
+`
+
+	var objs []map[string]any
+	var docs []*yaml.Node
+	for _, data := range []string{base, overlay} {
+		obj, doc, err := UnmarshalYAMLDocument([]byte(data))
+		assert.NoError(t, err)
+		objs = append(objs, obj)
+		docs = append(docs, doc)
+	}
+
+	merged := objs[0]
+	merged["image"].(map[string]any)["tag"] = "1.26"
+	merged["labels"] = objs[1]["labels"]
+	merged["ports"] = []any{mustParseNumber("80"), mustParseNumber("443")}
+	merged["env"] = []any{
+		map[string]any{"name": "LOG_LEVEL", "value": "debug"},
+		map[string]any{"name": "PORT", "value": "8080"},
+	}
+	merged["zone"] = "a"
+
+	out, err := MarshalYAMLFrom(merged, docs, YAMLFormat{})
+	assert.NoError(t, err)
+	assert.Equal(t, `# Chart values
+
+# Number of pods
+replicas: 1 # scaled by HPA
+defaults:
+  pullPolicy: IfNotPresent
+image:
+  pullPolicy: IfNotPresent
+  repository: nginx
+  tag: "1.26" # pinned
+ports: [80, 443]
+env:
+# set for all environments
+- name: LOG_LEVEL
+  value: debug
+- name: PORT
+  value: "8080"
+script: |
+  echo start
+# extra labels
+labels:
+  team: web # owner
+  app: shop
+zone: a
+`, string(out))
+
+	decoded, err := UnmarshalYAML(out)
+	assert.NoError(t, err)
+	assert.True(t, equalNumbers(merged, decoded))
+}
+
+func TestMarshalYAMLFromBooleans(t *testing.T) {
+	source := "enabled: yes # YAML 1.1\nswitch: On\nflags: [y, n]\nname: !!str yes\nquoted: 'off'\ncreated: 2001-12-14\n"
+	obj, doc, err := UnmarshalYAMLDocument([]byte(source))
+	assert.NoError(t, err)
+
+	out, err := MarshalYAMLFrom(obj, []*yaml.Node{doc}, YAMLFormat{})
+	assert.NoError(t, err)
+	assert.Equal(t, `enabled: true # YAML 1.1
+switch: true
+flags: [true, false]
+name: !!str yes
+quoted: 'off'
+created: 2001-12-14
+`, string(out))
+
+	decoded, err := UnmarshalYAML(out)
+	assert.NoError(t, err)
+	assert.Equal(t, obj, decoded)
+}
+
+// equalNumbers reports whether the values are deeply equal, comparing numbers by their values.
+func equalNumbers(a, b any) bool {
+	switch av := a.(type) {
+	case *big.Float:
+		bv, ok := b.(*big.Float)
+		return ok && av.Cmp(bv) == 0
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !equalNumbers(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for key := range av {
+			if !equalNumbers(av[key], bv[key]) {
+				return false
+			}
+		}
+		return true
+	}
+	return assert.ObjectsAreEqual(a, b)
+}
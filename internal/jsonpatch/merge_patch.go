@@ -0,0 +1,34 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonpatch
+
+// MergePatch applies the patch to the target as defined by RFC 7386 JSON Merge Patch.
+// Null values in the patch delete keys, while arrays and other values replace the target
+// wholesale. Neither the target nor the patch are modified.
+func MergePatch(target, patch any) any {
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]any)
+	if !ok {
+		targetObj = map[string]any{}
+	}
+
+	result := make(map[string]any, len(targetObj)+len(patchObj))
+	for k, v := range targetObj {
+		result[k] = v
+	}
+
+	for k, v := range patchObj {
+		if v == nil {
+			delete(result, k)
+			continue
+		}
+		result[k] = MergePatch(result[k], v)
+	}
+
+	return result
+}
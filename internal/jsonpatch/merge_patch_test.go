@@ -0,0 +1,130 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonpatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test cases taken from RFC 7386 Appendix A.
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   any
+		patch    any
+		expected any
+	}{
+		{
+			name:     "replace value",
+			target:   map[string]any{"a": "b"},
+			patch:    map[string]any{"a": "c"},
+			expected: map[string]any{"a": "c"},
+		},
+		{
+			name:     "add value",
+			target:   map[string]any{"a": "b"},
+			patch:    map[string]any{"b": "c"},
+			expected: map[string]any{"a": "b", "b": "c"},
+		},
+		{
+			name:     "delete value",
+			target:   map[string]any{"a": "b"},
+			patch:    map[string]any{"a": nil},
+			expected: map[string]any{},
+		},
+		{
+			name:     "delete one of values",
+			target:   map[string]any{"a": "b", "b": "c"},
+			patch:    map[string]any{"a": nil},
+			expected: map[string]any{"b": "c"},
+		},
+		{
+			name:     "replace list",
+			target:   map[string]any{"a": []any{"b"}},
+			patch:    map[string]any{"a": "c"},
+			expected: map[string]any{"a": "c"},
+		},
+		{
+			name:     "replace with list",
+			target:   map[string]any{"a": "c"},
+			patch:    map[string]any{"a": []any{"b"}},
+			expected: map[string]any{"a": []any{"b"}},
+		},
+		{
+			name:     "nested merge",
+			target:   map[string]any{"a": map[string]any{"b": "c"}},
+			patch:    map[string]any{"a": map[string]any{"b": "d", "c": nil}},
+			expected: map[string]any{"a": map[string]any{"b": "d"}},
+		},
+		{
+			name:     "list of objects is replaced",
+			target:   map[string]any{"a": []any{map[string]any{"b": "c"}}},
+			patch:    map[string]any{"a": []any{1.0}},
+			expected: map[string]any{"a": []any{1.0}},
+		},
+		{
+			name:     "scalar target",
+			target:   []any{"a", "b"},
+			patch:    []any{"c", "d"},
+			expected: []any{"c", "d"},
+		},
+		{
+			name:     "object patch of scalar target",
+			target:   []any{"a"},
+			patch:    map[string]any{"a": "b"},
+			expected: map[string]any{"a": "b"},
+		},
+		{
+			name:     "object patch of string target",
+			target:   "a",
+			patch:    map[string]any{"a": nil},
+			expected: map[string]any{},
+		},
+		{
+			name:     "null patch",
+			target:   map[string]any{"a": "foo"},
+			patch:    nil,
+			expected: nil,
+		},
+		{
+			name:     "string patch",
+			target:   map[string]any{"a": "foo"},
+			patch:    "bar",
+			expected: "bar",
+		},
+		{
+			name:     "null values are not added",
+			target:   map[string]any{"e": nil},
+			patch:    map[string]any{"a": 1.0},
+			expected: map[string]any{"e": nil, "a": 1.0},
+		},
+		{
+			name:     "nested null on missing key",
+			target:   []any{1.0, 2.0},
+			patch:    map[string]any{"a": "b", "c": nil},
+			expected: map[string]any{"a": "b"},
+		},
+		{
+			name:     "deeply nested null on missing key",
+			target:   map[string]any{},
+			patch:    map[string]any{"a": map[string]any{"bb": map[string]any{"ccc": nil}}},
+			expected: map[string]any{"a": map[string]any{"bb": map[string]any{}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MergePatch(tt.target, tt.patch))
+		})
+	}
+}
+
+func TestMergePatch_DoesNotModifyTarget(t *testing.T) {
+	target := map[string]any{"a": map[string]any{"b": "c"}}
+	MergePatch(target, map[string]any{"a": map[string]any{"b": nil}})
+
+	assert.Equal(t, map[string]any{"a": map[string]any{"b": "c"}}, target)
+}
@@ -0,0 +1,444 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonpatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Operation is a single RFC 6902 JSON Patch operation.
+type Operation struct {
+	Op       string
+	Path     string
+	From     string
+	Value    any
+	HasValue bool
+}
+
+// DecodeOperations decodes a list of operation objects, validating required members.
+func DecodeOperations(v any) ([]Operation, error) {
+	list, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("list of operations required, got: %s", jsonType(v))
+	}
+
+	ops := make([]Operation, len(list))
+	for i, elem := range list {
+		obj, ok := elem.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("operation %d must be object, got: %s", i+1, jsonType(elem))
+		}
+
+		op := Operation{}
+		for _, member := range []struct {
+			name     string
+			dst      *string
+			required bool
+		}{
+			{"op", &op.Op, true},
+			{"path", &op.Path, true},
+			{"from", &op.From, false},
+		} {
+			val, ok := obj[member.name]
+			if !ok || val == nil {
+				if member.required {
+					return nil, fmt.Errorf("operation %d is missing %q member", i+1, member.name)
+				}
+				continue
+			}
+			if *member.dst, ok = val.(string); !ok {
+				return nil, fmt.Errorf("operation %d %q member must be string, got: %s", i+1, member.name, jsonType(val))
+			}
+		}
+
+		op.Value, op.HasValue = obj["value"]
+
+		switch op.Op {
+		case "add", "replace", "test":
+			if !op.HasValue {
+				return nil, fmt.Errorf("operation %d (%s) is missing \"value\" member", i+1, op.Op)
+			}
+		case "move", "copy":
+			if obj["from"] == nil {
+				return nil, fmt.Errorf("operation %d (%s) is missing \"from\" member", i+1, op.Op)
+			}
+		case "remove":
+		default:
+			return nil, fmt.Errorf("operation %d has unsupported op %q", i+1, op.Op)
+		}
+
+		ops[i] = op
+	}
+
+	return ops, nil
+}
+
+// Apply applies the operations to the document as defined by RFC 6902 JSON Patch. The document
+// isn't modified; the operations are applied to a copy, which is returned only if all operations succeed.
+func Apply(doc any, ops []Operation) (any, error) {
+	doc = deepCopy(doc)
+
+	for i, op := range ops {
+		var err error
+		if doc, err = op.apply(doc); err != nil {
+			return nil, fmt.Errorf("operation %d (%s): %w", i+1, op.Op, err)
+		}
+	}
+
+	return doc, nil
+}
+
+func (op Operation) apply(doc any) (any, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add":
+		return add(doc, path, deepCopy(op.Value))
+
+	case "remove":
+		doc, _, err := remove(doc, path)
+		return doc, err
+
+	case "replace":
+		if _, err := get(doc, path); err != nil {
+			return nil, err
+		}
+		return set(doc, path, deepCopy(op.Value))
+
+	case "move":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		if len(from) < len(path) && reflect.DeepEqual(from, path[:len(from)]) {
+			return nil, fmt.Errorf("can't move %q into its own child %q", op.From, op.Path)
+		}
+
+		doc, value, err := remove(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+
+	case "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, deepCopy(value))
+
+	case "test":
+		value, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !equal(value, op.Value) {
+			return nil, fmt.Errorf("test failed at %q: value %s is not equal to %s", op.Path, jsonText(value), jsonText(op.Value))
+		}
+		return doc, nil
+	}
+
+	return nil, fmt.Errorf("unsupported op %q", op.Op)
+}
+
+// pointer is a parsed RFC 6901 JSON Pointer.
+type pointer []string
+
+func parsePointer(s string) (pointer, error) {
+	if s == "" {
+		return pointer{}, nil
+	}
+
+	if !strings.HasPrefix(s, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must be empty or start with '/'", s)
+	}
+
+	tokens := strings.Split(s[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+
+	return tokens, nil
+}
+
+func (p pointer) String() string {
+	var sb strings.Builder
+	for _, token := range p {
+		sb.WriteString("/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(token))
+	}
+	return sb.String()
+}
+
+// arrayIndex parses an array index token, allowing index == size for the "-" token and
+// for insertion positions.
+func arrayIndex(token string, size int, insert bool) (int, error) {
+	if token == "-" && insert {
+		return size, nil
+	}
+
+	if token == "" || (len(token) > 1 && token[0] == '0') || strings.TrimLeft(token, "0123456789") != "" {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	index, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	if index > size || (index == size && !insert) {
+		return 0, fmt.Errorf("array index %d out of bounds", index)
+	}
+
+	return index, nil
+}
+
+func get(doc any, path pointer) (any, error) {
+	for i, token := range path {
+		switch node := doc.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path %q not found", path[:i+1])
+			}
+			doc = value
+
+		case []any:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, fmt.Errorf("path %q not found: %w", path[:i+1], err)
+			}
+			doc = node[index]
+
+		default:
+			return nil, fmt.Errorf("path %q not found", path[:i+1])
+		}
+	}
+
+	return doc, nil
+}
+
+// update calls fn with the container referenced by the parent of path and the last path token,
+// storing the container returned by fn back into the document.
+func update(doc any, path pointer, fn func(container any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+
+	child, err := get(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+
+	child, err = update(child, path[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+
+	switch node := doc.(type) {
+	case map[string]any:
+		node[path[0]] = child
+	case []any:
+		index, _ := arrayIndex(path[0], len(node), false) // validated by get
+		node[index] = child
+	}
+
+	return doc, nil
+}
+
+func add(doc any, path pointer, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return update(doc, path, func(container any, token string) (any, error) {
+		switch node := container.(type) {
+		case map[string]any:
+			node[token] = value
+			return node, nil
+
+		case []any:
+			index, err := arrayIndex(token, len(node), true)
+			if err != nil {
+				return nil, fmt.Errorf("can't add to %q: %w", path, err)
+			}
+			node = append(node[:index], append([]any{value}, node[index:]...)...)
+			return node, nil
+		}
+
+		return nil, fmt.Errorf("can't add to %q: parent is not an object or array", path)
+	})
+}
+
+func set(doc any, path pointer, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return update(doc, path, func(container any, token string) (any, error) {
+		switch node := container.(type) {
+		case map[string]any:
+			node[token] = value
+			return node, nil
+
+		case []any:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, fmt.Errorf("can't replace %q: %w", path, err)
+			}
+			node[index] = value
+			return node, nil
+		}
+
+		return nil, fmt.Errorf("can't replace %q: parent is not an object or array", path)
+	})
+}
+
+func remove(doc any, path pointer) (any, any, error) {
+	if len(path) == 0 {
+		return nil, doc, nil
+	}
+
+	var removed any
+	doc, err := update(doc, path, func(container any, token string) (any, error) {
+		switch node := container.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path %q not found", path)
+			}
+			removed = value
+			delete(node, token)
+			return node, nil
+
+		case []any:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, fmt.Errorf("path %q not found: %w", path, err)
+			}
+			removed = node[index]
+			return append(node[:index], node[index+1:]...), nil
+		}
+
+		return nil, fmt.Errorf("path %q not found", path)
+	})
+
+	return doc, removed, err
+}
+
+func deepCopy(v any) any {
+	switch vv := v.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(vv))
+		for k, elem := range vv {
+			result[k] = deepCopy(elem)
+		}
+		return result
+
+	case []any:
+		result := make([]any, len(vv))
+		for i, elem := range vv {
+			result[i] = deepCopy(elem)
+		}
+		return result
+	}
+
+	return v
+}
+
+// equal reports whether the values are deeply equal, comparing numbers by their values.
+func equal(a, b any) bool {
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for key, elem := range av {
+			other, ok := bv[key]
+			if !ok || !equal(elem, other) {
+				return false
+			}
+		}
+		return true
+
+	case []any:
+		bv, ok := b.([]any)
+		return ok && slices.EqualFunc(av, bv, equal)
+
+	case *big.Float:
+		bv, ok := b.(*big.Float)
+		return ok && av.Cmp(bv) == 0
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// jsonType returns the JSON name of the value type, for error messages.
+func jsonType(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case *big.Float, float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// jsonText returns the value encoded as JSON, for error messages.
+func jsonText(v any) string {
+	data, err := json.Marshal(jsonValue(v))
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// jsonValue converts numbers of the value to JSON numbers written exactly.
+func jsonValue(v any) any {
+	switch vv := v.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(vv))
+		for key, elem := range vv {
+			result[key] = jsonValue(elem)
+		}
+		return result
+
+	case []any:
+		result := make([]any, len(vv))
+		for i, elem := range vv {
+			result[i] = jsonValue(elem)
+		}
+		return result
+
+	case *big.Float:
+		if vv.IsInt() {
+			return json.Number(vv.Text('f', 0))
+		}
+		return json.Number(vv.Text('g', -1))
+	}
+
+	return v
+}
@@ -0,0 +1,230 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonpatch
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test cases based on RFC 6902 Appendix A.
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      any
+		ops      []any
+		expected any
+		err      string
+	}{
+		{
+			name:     "add object member",
+			doc:      map[string]any{"foo": "bar"},
+			ops:      []any{map[string]any{"op": "add", "path": "/baz", "value": "qux"}},
+			expected: map[string]any{"foo": "bar", "baz": "qux"},
+		},
+		{
+			name:     "add array element",
+			doc:      map[string]any{"foo": []any{"bar", "baz"}},
+			ops:      []any{map[string]any{"op": "add", "path": "/foo/1", "value": "qux"}},
+			expected: map[string]any{"foo": []any{"bar", "qux", "baz"}},
+		},
+		{
+			name:     "add to the end of array",
+			doc:      map[string]any{"foo": []any{"bar"}},
+			ops:      []any{map[string]any{"op": "add", "path": "/foo/-", "value": []any{"abc", "def"}}},
+			expected: map[string]any{"foo": []any{"bar", []any{"abc", "def"}}},
+		},
+		{
+			name:     "remove object member",
+			doc:      map[string]any{"baz": "qux", "foo": "bar"},
+			ops:      []any{map[string]any{"op": "remove", "path": "/baz"}},
+			expected: map[string]any{"foo": "bar"},
+		},
+		{
+			name:     "remove array element",
+			doc:      map[string]any{"foo": []any{"bar", "qux", "baz"}},
+			ops:      []any{map[string]any{"op": "remove", "path": "/foo/1"}},
+			expected: map[string]any{"foo": []any{"bar", "baz"}},
+		},
+		{
+			name:     "replace value",
+			doc:      map[string]any{"baz": "qux", "foo": "bar"},
+			ops:      []any{map[string]any{"op": "replace", "path": "/baz", "value": "boo"}},
+			expected: map[string]any{"baz": "boo", "foo": "bar"},
+		},
+		{
+			name: "move value",
+			doc: map[string]any{
+				"foo": map[string]any{"bar": "baz", "waldo": "fred"},
+				"qux": map[string]any{"corge": "grault"},
+			},
+			ops: []any{map[string]any{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}},
+			expected: map[string]any{
+				"foo": map[string]any{"bar": "baz"},
+				"qux": map[string]any{"corge": "grault", "thud": "fred"},
+			},
+		},
+		{
+			name:     "move array element",
+			doc:      map[string]any{"foo": []any{"all", "grass", "cows", "eat"}},
+			ops:      []any{map[string]any{"op": "move", "from": "/foo/1", "path": "/foo/3"}},
+			expected: map[string]any{"foo": []any{"all", "cows", "eat", "grass"}},
+		},
+		{
+			name:     "copy value",
+			doc:      map[string]any{"foo": map[string]any{"bar": "baz"}},
+			ops:      []any{map[string]any{"op": "copy", "from": "/foo", "path": "/qux"}},
+			expected: map[string]any{"foo": map[string]any{"bar": "baz"}, "qux": map[string]any{"bar": "baz"}},
+		},
+		{
+			name: "test success",
+			doc:  map[string]any{"baz": "qux", "foo": []any{"a", 2.0, "c"}},
+			ops: []any{
+				map[string]any{"op": "test", "path": "/baz", "value": "qux"},
+				map[string]any{"op": "test", "path": "/foo/1", "value": 2.0},
+			},
+			expected: map[string]any{"baz": "qux", "foo": []any{"a", 2.0, "c"}},
+		},
+		{
+			name: "test failure",
+			doc:  map[string]any{"baz": "qux"},
+			ops:  []any{map[string]any{"op": "test", "path": "/baz", "value": "bar"}},
+			err:  `operation 1 (test): test failed at "/baz": value "qux" is not equal to "bar"`,
+		},
+		{
+			name: "test failure of an object",
+			doc:  map[string]any{"baz": map[string]any{"a": big.NewFloat(1), "b": []any{"x"}}},
+			ops:  []any{map[string]any{"op": "test", "path": "/baz", "value": map[string]any{"a": big.NewFloat(1.5)}}},
+			err:  `operation 1 (test): test failed at "/baz": value {"a":1,"b":["x"]} is not equal to {"a":1.5}`,
+		},
+		{
+			name:     "escaped pointer",
+			doc:      map[string]any{"/": 9.0, "~1": 10.0},
+			ops:      []any{map[string]any{"op": "replace", "path": "/~01", "value": 11.0}},
+			expected: map[string]any{"/": 9.0, "~1": 11.0},
+		},
+		{
+			name:     "replace whole document",
+			doc:      map[string]any{"foo": "bar"},
+			ops:      []any{map[string]any{"op": "replace", "path": "", "value": []any{"baz"}}},
+			expected: []any{"baz"},
+		},
+		{
+			name: "add to nonexistent target",
+			doc:  map[string]any{"foo": "bar"},
+			ops:  []any{map[string]any{"op": "add", "path": "/baz/bat", "value": "qux"}},
+			err:  `operation 1 (add): path "/baz" not found`,
+		},
+		{
+			name: "remove nonexistent member",
+			doc:  map[string]any{"foo": "bar"},
+			ops:  []any{map[string]any{"op": "remove", "path": "/baz"}},
+			err:  `operation 1 (remove): path "/baz" not found`,
+		},
+		{
+			name: "array index out of bounds",
+			doc:  map[string]any{"foo": []any{"bar"}},
+			ops:  []any{map[string]any{"op": "add", "path": "/foo/2", "value": "baz"}},
+			err:  `operation 1 (add): can't add to "/foo/2": array index 2 out of bounds`,
+		},
+		{
+			name: "leading zero array index",
+			doc:  map[string]any{"foo": []any{"bar", "baz"}},
+			ops:  []any{map[string]any{"op": "replace", "path": "/foo/01", "value": "qux"}},
+			err:  `operation 1 (replace): path "/foo/01" not found: invalid array index "01"`,
+		},
+		{
+			name: "move into own child",
+			doc:  map[string]any{"foo": map[string]any{"bar": "baz"}},
+			ops:  []any{map[string]any{"op": "move", "from": "/foo", "path": "/foo/bar/qux"}},
+			err:  `operation 1 (move): can't move "/foo" into its own child "/foo/bar/qux"`,
+		},
+		{
+			name: "invalid pointer",
+			doc:  map[string]any{"foo": "bar"},
+			ops:  []any{map[string]any{"op": "remove", "path": "foo"}},
+			err:  `operation 1 (remove): invalid JSON pointer "foo": must be empty or start with '/'`,
+		},
+		{
+			name: "operations are atomic",
+			doc:  map[string]any{"foo": "bar"},
+			ops: []any{
+				map[string]any{"op": "add", "path": "/baz", "value": "qux"},
+				map[string]any{"op": "test", "path": "/foo", "value": "baz"},
+			},
+			err: `operation 2 (test): test failed at "/foo"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops, err := DecodeOperations(tt.ops)
+			assert.NoError(t, err)
+
+			doc := deepCopy(tt.doc)
+			result, err := Apply(doc, ops)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+			assert.Equal(t, tt.doc, doc)
+		})
+	}
+}
+
+func TestDecodeOperations(t *testing.T) {
+	tests := []struct {
+		name string
+		ops  any
+		err  string
+	}{
+		{
+			name: "not a list",
+			ops:  map[string]any{"op": "add"},
+			err:  "list of operations required, got: object",
+		},
+		{
+			name: "not an object",
+			ops:  []any{"add"},
+			err:  "operation 1 must be object, got: string",
+		},
+		{
+			name: "missing op",
+			ops:  []any{map[string]any{"path": "/a"}},
+			err:  `operation 1 is missing "op" member`,
+		},
+		{
+			name: "unsupported op",
+			ops:  []any{map[string]any{"op": "merge", "path": "/a"}},
+			err:  `operation 1 has unsupported op "merge"`,
+		},
+		{
+			name: "missing value",
+			ops:  []any{map[string]any{"op": "add", "path": "/a"}},
+			err:  `operation 1 (add) is missing "value" member`,
+		},
+		{
+			name: "missing from",
+			ops:  []any{map[string]any{"op": "copy", "path": "/a", "from": nil}},
+			err:  `operation 1 (copy) is missing "from" member`,
+		},
+		{
+			name: "invalid path type",
+			ops:  []any{map[string]any{"op": "remove", "path": 1.0}},
+			err:  `operation 1 "path" member must be string, got: number`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeOperations(tt.ops)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,284 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package pathexpr
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+type selectorKind int
+
+const (
+	// selectKey matches the object key, e.g. `spec` or `"app.kubernetes.io/name"`.
+	selectKey selectorKind = iota
+	// selectAnyKey matches any object key, `*`.
+	selectAnyKey
+	// selectAnyDepth matches any number of keys and indexes including none, `**`.
+	selectAnyDepth
+	// selectIndex matches the list index, e.g. `[0]`.
+	selectIndex
+	// selectAnyIndex matches any list index, `[*]`.
+	selectAnyIndex
+	// selectPredicate matches list elements which are objects with the field set to the value, e.g. `[name=app]`.
+	selectPredicate
+)
+
+type selector struct {
+	kind  selectorKind
+	key   string
+	index int
+	field string
+	value string
+}
+
+// Expression matches paths of values in documents. Keys are separated by dots and may be quoted if they
+// contain special characters, `*` matches any key, `**` any number of keys and indexes, and list elements
+// are selected by index `[0]`, any index `[*]` or by a field value `[name=app]`.
+type Expression struct {
+	expr      string
+	selectors []selector
+}
+
+// Parse parses the path expression.
+func Parse(expr string) (Expression, error) {
+	p := parser{expr: expr}
+	selectors := []selector{}
+
+	for p.pos < len(expr) {
+		if expr[p.pos] == '[' {
+			s, err := p.listSelector()
+			if err != nil {
+				return Expression{}, err
+			}
+			selectors = append(selectors, s)
+			continue
+		}
+
+		if len(selectors) > 0 {
+			if expr[p.pos] != '.' {
+				return Expression{}, p.errorf("expected '.' or '[' at position %d", p.pos+1)
+			}
+			p.pos++
+		}
+
+		start := p.pos
+		key, quoted, err := p.token(`.["`)
+		if err != nil {
+			return Expression{}, err
+		}
+
+		switch {
+		case quoted:
+			selectors = append(selectors, selector{kind: selectKey, key: key})
+		case key == "":
+			return Expression{}, p.errorf("empty key at position %d", start+1)
+		case key == "*":
+			selectors = append(selectors, selector{kind: selectAnyKey})
+		case key == "**":
+			selectors = append(selectors, selector{kind: selectAnyDepth})
+		default:
+			selectors = append(selectors, selector{kind: selectKey, key: key})
+		}
+	}
+
+	if len(selectors) == 0 {
+		return Expression{}, p.errorf("path must not be empty")
+	}
+
+	return Expression{expr: expr, selectors: selectors}, nil
+}
+
+type parser struct {
+	expr string
+	pos  int
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("invalid path %q: %s", p.expr, fmt.Sprintf(format, args...))
+}
+
+// token reads a quoted string, or a bare string ending before any of the stop characters.
+func (p *parser) token(stop string) (s string, quoted bool, err error) {
+	if p.pos >= len(p.expr) || p.expr[p.pos] != '"' {
+		end := strings.IndexAny(p.expr[p.pos:], stop)
+		if end < 0 {
+			end = len(p.expr) - p.pos
+		}
+		s = p.expr[p.pos : p.pos+end]
+		p.pos += end
+		return s, false, nil
+	}
+
+	start := p.pos
+	var sb strings.Builder
+	for p.pos++; p.pos < len(p.expr); p.pos++ {
+		switch c := p.expr[p.pos]; {
+		case c == '\\' && p.pos+1 < len(p.expr):
+			p.pos++
+			sb.WriteByte(p.expr[p.pos])
+		case c == '"':
+			p.pos++
+			return sb.String(), true, nil
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return "", false, p.errorf("unterminated quoted string at position %d", start+1)
+}
+
+// listSelector reads a list selector enclosed in brackets.
+func (p *parser) listSelector() (selector, error) {
+	start := p.pos
+	p.pos++
+
+	token, quoted, err := p.token("=]")
+	if err != nil {
+		return selector{}, err
+	}
+	if p.pos >= len(p.expr) {
+		return selector{}, p.errorf("unterminated list selector at position %d", start+1)
+	}
+
+	if p.expr[p.pos] == ']' {
+		p.pos++
+		if token == "*" && !quoted {
+			return selector{kind: selectAnyIndex}, nil
+		}
+
+		index, err := strconv.Atoi(token)
+		if err != nil || index < 0 || quoted {
+			return selector{}, p.errorf("list selector must be a non-negative index, '*' or a field=value predicate, got %q", p.expr[start+1:p.pos-1])
+		}
+		return selector{kind: selectIndex, index: index}, nil
+	}
+
+	// predicate
+	if token == "" {
+		return selector{}, p.errorf("empty predicate field at position %d", start+2)
+	}
+	p.pos++
+
+	value, _, err := p.token("]")
+	if err != nil {
+		return selector{}, err
+	}
+	if p.pos >= len(p.expr) || p.expr[p.pos] != ']' {
+		return selector{}, p.errorf("unterminated list selector at position %d", start+1)
+	}
+	p.pos++
+
+	return selector{kind: selectPredicate, field: token, value: value}, nil
+}
+
+// String returns the expression as parsed.
+func (e Expression) String() string {
+	return e.expr
+}
+
+// Match reports whether the expression matches the path.
+func (e Expression) Match(p Path) bool {
+	return match(e.selectors, p)
+}
+
+func match(selectors []selector, p Path) bool {
+	for len(selectors) > 0 {
+		s := selectors[0]
+
+		if s.kind == selectAnyDepth {
+			for i := 0; i <= len(p); i++ {
+				if match(selectors[1:], p[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(p) == 0 || !s.matches(p[0]) {
+			return false
+		}
+		selectors, p = selectors[1:], p[1:]
+	}
+
+	return len(p) == 0
+}
+
+func (s selector) matches(seg Segment) bool {
+	switch s.kind {
+	case selectKey:
+		return !seg.IsIndex && seg.Key == s.key
+	case selectAnyKey:
+		return !seg.IsIndex
+	case selectIndex:
+		return seg.IsIndex && seg.Index == s.index
+	case selectAnyIndex:
+		return seg.IsIndex
+	case selectPredicate:
+		obj, ok := seg.Element.(map[string]any)
+		if !seg.IsIndex || !ok {
+			return false
+		}
+		value, ok := formatScalar(obj[s.field])
+		return ok && value == s.value
+	}
+	return false
+}
+
+// formatScalar formats a string, number or bool value as written in predicates.
+func formatScalar(v any) (string, bool) {
+	switch vv := v.(type) {
+	case string:
+		return vv, true
+	case *big.Float:
+		return vv.Text('f', -1), true
+	case float64:
+		return strconv.FormatFloat(vv, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(vv), true
+	}
+	return "", false
+}
+
+// Wildcards returns a measure of how general the expression is, used to order expressions from the least
+// specific: the number of `*` and `[*]` selectors, with `**` counting twice.
+func (e Expression) Wildcards() int {
+	count := 0
+	for _, s := range e.selectors {
+		switch s.kind {
+		case selectAnyKey, selectAnyIndex:
+			count++
+		case selectAnyDepth:
+			count += 2
+		}
+	}
+	return count
+}
+
+// Path returns the path matched by an expression consisting only of keys and indexes.
+func (e Expression) Path() (Path, error) {
+	p := make(Path, len(e.selectors))
+	for i, s := range e.selectors {
+		switch s.kind {
+		case selectKey:
+			p[i] = Segment{Key: s.key}
+		case selectIndex:
+			p[i] = Segment{Index: s.index, IsIndex: true}
+		default:
+			return nil, fmt.Errorf("invalid path %q: wildcards and predicates aren't allowed", e.expr)
+		}
+	}
+	return p, nil
+}
+
+// ParsePath parses an expression consisting only of keys and indexes, e.g. `metadata.name`, returning the path it matches.
+func ParsePath(expr string) (Path, error) {
+	e, err := Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	return e.Path()
+}
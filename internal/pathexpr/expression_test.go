@@ -0,0 +1,139 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package pathexpr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr string
+		err  string
+	}{
+		{expr: "spec.containers[*].env"},
+		{expr: "**.containers[name=app]"},
+		{expr: `metadata.labels."app.kubernetes.io/name"`},
+		{expr: `items["a key"="a \"value\""]`},
+		{expr: "[0][1]"},
+		{expr: "", err: `invalid path "": path must not be empty`},
+		{expr: "a..b", err: `invalid path "a..b": empty key at position 3`},
+		{expr: "a.", err: `invalid path "a.": empty key at position 3`},
+		{expr: "a[0", err: `invalid path "a[0": unterminated list selector at position 2`},
+		{expr: "a[-1]", err: `invalid path "a[-1]": list selector must be a non-negative index, '*' or a field=value predicate, got "-1"`},
+		{expr: "a[x]", err: `invalid path "a[x]": list selector must be a non-negative index, '*' or a field=value predicate, got "x"`},
+		{expr: "a[=x]", err: `invalid path "a[=x]": empty predicate field at position 3`},
+		{expr: "a[name=x", err: `invalid path "a[name=x": unterminated list selector at position 2`},
+		{expr: "a[0]b", err: `invalid path "a[0]b": expected '.' or '[' at position 5`},
+		{expr: `a."b`, err: `invalid path "a.\"b": unterminated quoted string at position 3`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := Parse(tt.expr)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expr, e.String())
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	app := map[string]any{"name": "app", "port": float64(80), "enabled": true}
+
+	tests := []struct {
+		name     string
+		expr     string
+		path     Path
+		expected bool
+	}{
+		{name: "key", expr: "a.b", path: Path{{Key: "a"}, {Key: "b"}}, expected: true},
+		{name: "different key", expr: "a.b", path: Path{{Key: "a"}, {Key: "c"}}},
+		{name: "shorter path", expr: "a.b", path: Path{{Key: "a"}}},
+		{name: "longer path", expr: "a", path: Path{{Key: "a"}, {Key: "b"}}},
+		{name: "any key", expr: "*.b", path: Path{{Key: "x"}, {Key: "b"}}, expected: true},
+		{name: "any key doesn't match index", expr: "a.*", path: Path{{Key: "a"}, {Index: 0, IsIndex: true}}},
+		{name: "index", expr: "a[1]", path: Path{{Key: "a"}, {Index: 1, IsIndex: true}}, expected: true},
+		{name: "different index", expr: "a[1]", path: Path{{Key: "a"}, {Index: 0, IsIndex: true}}},
+		{name: "any index", expr: "a[*]", path: Path{{Key: "a"}, {Index: 3, IsIndex: true}}, expected: true},
+		{name: "any index doesn't match key", expr: "a[*]", path: Path{{Key: "a"}, {Key: "b"}}},
+		{name: "any depth matches nothing", expr: "**.a", path: Path{{Key: "a"}}, expected: true},
+		{name: "any depth matches keys and indexes", expr: "**.env", path: Path{{Key: "spec"}, {Key: "containers"}, {Index: 0, IsIndex: true}, {Key: "env"}}, expected: true},
+		{name: "any depth in the middle", expr: "spec.**.env", path: Path{{Key: "spec"}, {Key: "x"}, {Key: "env"}}, expected: true},
+		{name: "any depth at the end", expr: "spec.**", path: Path{{Key: "spec"}, {Key: "x"}, {Key: "y"}}, expected: true},
+		{name: "any depth requires the rest", expr: "spec.**.env", path: Path{{Key: "spec"}, {Key: "x"}}},
+		{name: "predicate", expr: "a[name=app]", path: Path{{Key: "a"}, {Index: 0, IsIndex: true, Element: app}}, expected: true},
+		{name: "predicate with number", expr: "a[port=80]", path: Path{{Key: "a"}, {Index: 0, IsIndex: true, Element: app}}, expected: true},
+		{name: "predicate with bool", expr: "a[enabled=true]", path: Path{{Key: "a"}, {Index: 0, IsIndex: true, Element: app}}, expected: true},
+		{name: "predicate with different value", expr: "a[name=web]", path: Path{{Key: "a"}, {Index: 0, IsIndex: true, Element: app}}},
+		{name: "predicate with missing field", expr: "a[image=app]", path: Path{{Key: "a"}, {Index: 0, IsIndex: true, Element: app}}},
+		{name: "predicate without element", expr: "a[name=app]", path: Path{{Key: "a"}, {Index: 0, IsIndex: true}}},
+		{name: "quoted key", expr: `labels."app.kubernetes.io/name"`, path: Path{{Key: "labels"}, {Key: "app.kubernetes.io/name"}}, expected: true},
+		{name: "quoted wildcard is a key", expr: `"*"`, path: Path{{Key: "x"}}},
+		{name: "quoted predicate value", expr: `a[name="a]b"]`, path: Path{{Key: "a"}, {Index: 0, IsIndex: true, Element: map[string]any{"name": "a]b"}}}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Parse(tt.expr)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, e.Match(tt.path))
+		})
+	}
+}
+
+func TestWildcards(t *testing.T) {
+	assert.Equal(t, 0, mustParse(t, "a[0][name=x]").Wildcards())
+	assert.Equal(t, 2, mustParse(t, "*.a[*]").Wildcards())
+	assert.Equal(t, 2, mustParse(t, "**.a").Wildcards())
+}
+
+func TestParsePath(t *testing.T) {
+	p, err := ParsePath(`metadata."a.b"[1]`)
+	assert.NoError(t, err)
+	assert.Equal(t, Path{{Key: "metadata"}, {Key: "a.b"}, {Index: 1, IsIndex: true}}, p)
+
+	_, err = ParsePath("a.*")
+	assert.EqualError(t, err, `invalid path "a.*": wildcards and predicates aren't allowed`)
+
+	_, err = ParsePath("a[name=x]")
+	assert.EqualError(t, err, `invalid path "a[name=x]": wildcards and predicates aren't allowed`)
+}
+
+func TestPath(t *testing.T) {
+	p := Path{}.Child("spec").Child("containers").Index(0).Child("app.kubernetes.io/name").Child("*")
+	assert.Equal(t, `spec.containers[0]."app.kubernetes.io/name"."*"`, p.String())
+	assert.Equal(t, 4, p.Depth())
+
+	e, err := Parse(p.String())
+	assert.NoError(t, err)
+	assert.True(t, e.Match(p))
+
+	doc := map[string]any{"spec": map[string]any{"containers": []any{map[string]any{"name": "app"}}}}
+	value, ok := mustParsePath(t, "spec.containers[0].name").Lookup(doc)
+	assert.True(t, ok)
+	assert.Equal(t, "app", value)
+
+	_, ok = mustParsePath(t, "spec.containers[1]").Lookup(doc)
+	assert.False(t, ok)
+}
+
+func mustParse(t *testing.T, expr string) Expression {
+	t.Helper()
+	e, err := Parse(expr)
+	assert.NoError(t, err)
+	return e
+}
+
+func mustParsePath(t *testing.T, expr string) Path {
+	t.Helper()
+	p, err := ParsePath(expr)
+	assert.NoError(t, err)
+	return p
+}
@@ -0,0 +1,107 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package pathexpr implements paths of values in decoded documents and path expressions matching them,
+// e.g. `spec.**.containers[name=app].env` or `metadata.labels."app.kubernetes.io/name"`.
+package pathexpr
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Segment is a single step in a document, either an object key or a list index.
+type Segment struct {
+	Key     string
+	Index   int
+	IsIndex bool
+	// Element is the list element at the index, used to match predicate selectors. It's optional,
+	// predicates never match elements which aren't set.
+	Element any
+}
+
+// Path is a location of a value in a document.
+type Path []Segment
+
+// Child returns the path of the object key. The path is copied, so paths of siblings don't share segments.
+func (p Path) Child(key string) Path {
+	return append(p[:len(p):len(p)], Segment{Key: key})
+}
+
+// Index returns the path of the list element at the index.
+func (p Path) Index(i int) Path {
+	return append(p[:len(p):len(p)], Segment{Index: i, IsIndex: true})
+}
+
+// Element returns the path of the list element at the index, holding the element to match predicate selectors.
+func (p Path) Element(i int, elem any) Path {
+	return append(p[:len(p):len(p)], Segment{Index: i, IsIndex: true, Element: elem})
+}
+
+// Depth returns the number of object keys in the path, not counting list indexes.
+func (p Path) Depth() int {
+	depth := 0
+	for _, s := range p {
+		if !s.IsIndex {
+			depth++
+		}
+	}
+	return depth
+}
+
+// String formats the path as an expression matching it, e.g. `spec.containers[0]."app.kubernetes.io/name"`.
+func (p Path) String() string {
+	var sb strings.Builder
+	for i, s := range p {
+		switch {
+		case s.IsIndex:
+			sb.WriteString("[" + strconv.Itoa(s.Index) + "]")
+		case i > 0:
+			sb.WriteString("." + formatKey(s.Key))
+		default:
+			sb.WriteString(formatKey(s.Key))
+		}
+	}
+	return sb.String()
+}
+
+// Lookup returns the value at the path, or false if it doesn't exist.
+func (p Path) Lookup(v any) (any, bool) {
+	for _, s := range p {
+		switch vv := v.(type) {
+		case map[string]any:
+			if s.IsIndex {
+				return nil, false
+			}
+			elem, ok := vv[s.Key]
+			if !ok {
+				return nil, false
+			}
+			v = elem
+
+		case []any:
+			if !s.IsIndex || s.Index >= len(vv) {
+				return nil, false
+			}
+			v = vv[s.Index]
+
+		default:
+			return nil, false
+		}
+	}
+
+	return v, true
+}
+
+// formatKey returns the key, quoted if it can't be written as a bare key of an expression.
+func formatKey(key string) string {
+	if key == "" || key == "*" || key == "**" || strings.ContainsAny(key, `.[]"`) {
+		return quote(key)
+	}
+	return key
+}
+
+// quote quotes the string, escaping only quotes and backslashes.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
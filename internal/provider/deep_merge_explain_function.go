@@ -0,0 +1,107 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	_ "embed"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lablabs/terraform-provider-lara-utils/internal/deepmerge"
+	"github.com/lablabs/terraform-provider-lara-utils/internal/helpers"
+)
+
+var (
+	_ function.Function = DeepMergeExplainFunction{}
+	//go:embed deep_merge_explain_function.md
+	deepMergeExplainFunctionDescription string
+)
+
+// DeepMergeExplainFunction merges objects like DeepMergeFunction, additionally reporting where the merged values came from.
+type DeepMergeExplainFunction struct {
+	DeepMergeFunction
+}
+
+func NewDeepMergeExplainFunction() function.Function {
+	return DeepMergeExplainFunction{}
+}
+
+func (fn DeepMergeExplainFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "deep_merge_explain"
+}
+
+func (fn DeepMergeExplainFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = deepmerge.NewFunctionDefinition(fn)
+}
+
+func (fn DeepMergeExplainFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	objs, err := fn.GetMergingObjects(ctx, req.Arguments)
+	if resp.Error = function.ConcatFuncErrors(err); resp.Error != nil {
+		return
+	}
+	if objs == nil {
+		resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, types.DynamicUnknown()))
+		return
+	}
+
+	opts, err := fn.GetMergingOptions(ctx, req.Arguments)
+	if resp.Error = function.ConcatFuncErrors(err); resp.Error != nil {
+		return
+	}
+
+	hints, err := fn.GetMergingTypes(ctx, req.Arguments)
+	if resp.Error = function.ConcatFuncErrors(err); resp.Error != nil {
+		return
+	}
+
+	explained, diags := deepmerge.Explain(ctx, objs, hints, *opts)
+	if diags.HasError() {
+		resp.Error = function.FuncErrorFromDiags(ctx, diags)
+		return
+	}
+
+	value, diags := fn.explainedValue(ctx, explained, hints)
+	if diags.HasError() {
+		resp.Error = function.FuncErrorFromDiags(ctx, diags)
+		return
+	}
+
+	result := types.DynamicValue(value)
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, &result))
+}
+
+// explainedValue decodes the explanation, rebuilding the merged result from the types of the merged
+// objects like FunctionResult, so it has the same type as the result of deep_merge.
+func (fn DeepMergeExplainFunction) explainedValue(ctx context.Context, explained map[string]any, hints []attr.Type) (attr.Value, diag.Diagnostics) {
+	result, diags := helpers.DecodeTyped(ctx, explained["result"], hints)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	sources, diags := helpers.DecodeScalar(ctx, explained["sources"])
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	return types.ObjectValue(
+		map[string]attr.Type{"result": result.Type(ctx), "sources": sources.Type(ctx)},
+		map[string]attr.Value{"result": result, "sources": sources},
+	)
+}
+
+func (fn DeepMergeExplainFunction) FunctionSummary() string {
+	return "Deep merge objects and explain where merged values came from"
+}
+
+func (fn DeepMergeExplainFunction) FunctionDescription() string {
+	return deepMergeExplainFunctionDescription
+}
+
+// GetMergingOptions decodes the merging options, including the labels option accepted only by this function.
+func (fn DeepMergeExplainFunction) GetMergingOptions(ctx context.Context, args function.ArgumentsData) (*deepmerge.DeepMergeOptions, *function.FuncError) {
+	return decodeMergingOptions(ctx, args, "deep_merge_explain")
+}
@@ -0,0 +1,171 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestDeepMergeExplainFunction(t *testing.T) {
+	locals := `
+		locals {
+			defaults = {
+				replicas = 1
+				image    = { repository = "app", tag = "1.0.0" }
+				tags     = ["base"]
+			}
+			production = {
+				replicas = 3
+				tags     = ["production"]
+			}
+			release = {
+				image = { tag = "1.1.0" }
+			}
+		}
+	`
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: locals + `
+					output "test" {
+						value = provider::lara-utils::deep_merge_explain([local.defaults, local.production, local.release])
+					}
+				`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test",
+						knownvalue.MapExact(map[string]knownvalue.Check{
+							"result": knownvalue.MapExact(map[string]knownvalue.Check{
+								"replicas": knownvalue.Int64Exact(3),
+								"image": knownvalue.MapExact(map[string]knownvalue.Check{
+									"repository": knownvalue.StringExact("app"),
+									"tag":        knownvalue.StringExact("1.1.0"),
+								}),
+								"tags": knownvalue.ListExact([]knownvalue.Check{
+									knownvalue.StringExact("production"),
+								}),
+							}),
+							"sources": knownvalue.MapExact(map[string]knownvalue.Check{
+								"replicas": knownvalue.MapExact(map[string]knownvalue.Check{
+									"source": knownvalue.Int64Exact(1),
+									"overridden": knownvalue.ListExact([]knownvalue.Check{
+										knownvalue.MapExact(map[string]knownvalue.Check{
+											"source": knownvalue.Int64Exact(0),
+											"value":  knownvalue.Int64Exact(1),
+										}),
+									}),
+								}),
+								"image.repository": knownvalue.MapExact(map[string]knownvalue.Check{
+									"source":     knownvalue.Int64Exact(0),
+									"overridden": knownvalue.ListExact([]knownvalue.Check{}),
+								}),
+								"image.tag": knownvalue.MapExact(map[string]knownvalue.Check{
+									"source": knownvalue.Int64Exact(2),
+									"overridden": knownvalue.ListExact([]knownvalue.Check{
+										knownvalue.MapExact(map[string]knownvalue.Check{
+											"source": knownvalue.Int64Exact(0),
+											"value":  knownvalue.StringExact("1.0.0"),
+										}),
+									}),
+								}),
+								"tags[0]": knownvalue.MapExact(map[string]knownvalue.Check{
+									"source": knownvalue.Int64Exact(1),
+									"overridden": knownvalue.ListExact([]knownvalue.Check{
+										knownvalue.MapExact(map[string]knownvalue.Check{
+											"source": knownvalue.Int64Exact(0),
+											"value":  knownvalue.StringExact("base"),
+										}),
+									}),
+								}),
+							}),
+						}),
+					),
+				},
+			},
+			{
+				Config: locals + `
+					output "test" {
+						value = provider::lara-utils::deep_merge_explain(
+							[local.defaults, local.production, local.release],
+							{ append_list = true, labels = ["defaults", "production", "release"] },
+						)
+					}
+				`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test",
+						knownvalue.MapPartial(map[string]knownvalue.Check{
+							"result": knownvalue.MapPartial(map[string]knownvalue.Check{
+								"tags": knownvalue.ListExact([]knownvalue.Check{
+									knownvalue.StringExact("base"),
+									knownvalue.StringExact("production"),
+								}),
+							}),
+							"sources": knownvalue.MapPartial(map[string]knownvalue.Check{
+								"tags[0]": knownvalue.MapExact(map[string]knownvalue.Check{
+									"source":     knownvalue.StringExact("defaults"),
+									"overridden": knownvalue.ListExact([]knownvalue.Check{}),
+								}),
+								"tags[1]": knownvalue.MapExact(map[string]knownvalue.Check{
+									"source":     knownvalue.StringExact("production"),
+									"overridden": knownvalue.ListExact([]knownvalue.Check{}),
+								}),
+								"image.tag": knownvalue.MapPartial(map[string]knownvalue.Check{
+									"source": knownvalue.StringExact("release"),
+								}),
+							}),
+						}),
+					),
+				},
+			},
+			{
+				Config: `
+					variable "objects" {
+						type = list(object({
+							labels = map(string)
+							ports  = set(number)
+						}))
+						default = [
+							{ labels = { app = "web" }, ports = [80] },
+							{ labels = { team = "platform" }, ports = [443] },
+						]
+					}
+
+					output "test" {
+						value = provider::lara-utils::deep_merge_explain(var.objects).result == provider::lara-utils::deep_merge(var.objects)
+					}
+				`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.Bool(true)),
+				},
+			},
+			{
+				Config: locals + `
+					output "test" {
+						value = provider::lara-utils::deep_merge_explain([local.defaults, local.production], { labels = ["defaults"] })
+					}
+				`,
+				ExpectError: regexp.MustCompile(`labels must have the same length as the list of objects`),
+			},
+			{
+				Config: locals + `
+					output "test" {
+						value = provider::lara-utils::deep_merge_explain([local.defaults, local.production], { on_conflict = "error" })
+					}
+				`,
+				ExpectError: regexp.MustCompile(`replicas: set differently by arguments 1 and 2`),
+			},
+		},
+	})
+}
@@ -16,7 +16,6 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/lablabs/terraform-provider-lara-utils/internal/deepmerge"
 	"github.com/lablabs/terraform-provider-lara-utils/internal/helpers"
-	"github.com/mitchellh/mapstructure"
 )
 
 var (
@@ -56,27 +55,18 @@ func (fn DeepMergeFunction) FunctionObjectsParameter() function.Parameter {
 		Name:                "objects",
 		MarkdownDescription: "List of objects to merge",
 		AllowNullValue:      false,
-		AllowUnknownValues:  false,
+		AllowUnknownValues:  true,
 	}
 }
 
 func (fn DeepMergeFunction) GetMergingObjects(ctx context.Context, args function.ArgumentsData) ([]map[string]any, *function.FuncError) {
-	arg := types.Dynamic{}
-	if err := args.GetArgument(ctx, 0, &arg); err != nil {
+	elems, err := fn.getMergingElements(ctx, args)
+	if err != nil {
 		return nil, err
 	}
 
-	var elems []attr.Value
-	argVal := arg.UnderlyingValue()
-	switch argType := argVal.Type(ctx).(type) {
-	case basetypes.SetType:
-		elems = argVal.(basetypes.SetValue).Elements() //nolint:forcetypeassert
-	case basetypes.ListType:
-		elems = argVal.(basetypes.ListValue).Elements() //nolint:forcetypeassert
-	case basetypes.TupleType:
-		elems = argVal.(basetypes.TupleValue).Elements() //nolint:forcetypeassert
-	default:
-		return nil, function.NewArgumentFuncError(int64(0), fmt.Sprintf("list of objects required, got: %s", argType))
+	if elems == nil {
+		return nil, nil
 	}
 
 	objs := []map[string]any{}
@@ -85,22 +75,67 @@ func (fn DeepMergeFunction) GetMergingObjects(ctx context.Context, args function
 		if err != nil {
 			return nil, function.NewArgumentFuncError(int64(0), err.Error())
 		}
+		if helpers.IsUnknown(val) {
+			return nil, nil
+		}
 		if _, ok := val.(map[string]any); !ok {
 			return nil, function.NewArgumentFuncError(int64(0), fmt.Sprintf("merging argument %d must be object, got: %s", idx+1, reflect.TypeOf(val)))
 		}
 
-		obj := val.(map[string]any) //nolint:forcetypeassert
-		if len(obj) == 0 {
-			continue
+		objs = append(objs, val.(map[string]any)) //nolint:forcetypeassert
+	}
+
+	return objs, nil
+}
+
+// GetMergingTypes returns the types of the merged objects, used to rebuild maps, lists and sets in the result.
+func (fn DeepMergeFunction) GetMergingTypes(ctx context.Context, args function.ArgumentsData) ([]attr.Type, *function.FuncError) {
+	elems, err := fn.getMergingElements(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	hints := make([]attr.Type, len(elems))
+	for idx, elem := range elems {
+		if dv, ok := elem.(basetypes.DynamicValue); ok {
+			elem = dv.UnderlyingValue()
 		}
+		hints[idx] = elem.Type(ctx)
+	}
 
-		objs = append(objs, obj)
+	return hints, nil
+}
+
+// getMergingElements returns the elements of the objects argument, or nil if it's unknown.
+func (fn DeepMergeFunction) getMergingElements(ctx context.Context, args function.ArgumentsData) ([]attr.Value, *function.FuncError) {
+	arg := types.Dynamic{}
+	if err := args.GetArgument(ctx, 0, &arg); err != nil {
+		return nil, err
+	}
+	if arg.IsUnknown() || arg.IsUnderlyingValueUnknown() {
+		return nil, nil
 	}
 
-	return objs, nil
+	argVal := arg.UnderlyingValue()
+	switch argType := argVal.Type(ctx).(type) {
+	case basetypes.SetType:
+		return argVal.(basetypes.SetValue).Elements(), nil //nolint:forcetypeassert
+	case basetypes.ListType:
+		return argVal.(basetypes.ListValue).Elements(), nil //nolint:forcetypeassert
+	case basetypes.TupleType:
+		return argVal.(basetypes.TupleValue).Elements(), nil //nolint:forcetypeassert
+	default:
+		return nil, function.NewArgumentFuncError(int64(0), fmt.Sprintf("list of objects required, got: %s", argType))
+	}
 }
 
 func (fn DeepMergeFunction) GetMergingOptions(ctx context.Context, args function.ArgumentsData) (*deepmerge.DeepMergeOptions, *function.FuncError) {
+	return decodeMergingOptions(ctx, args, "deep_merge")
+}
+
+// decodeMergingOptions decodes the options objects of the arguments, accepting the options of the named
+// merge function.
+func decodeMergingOptions(ctx context.Context, args function.ArgumentsData, name string) (*deepmerge.DeepMergeOptions, *function.FuncError) {
 	arg := basetypes.TupleValue{}
 	if err := args.GetArgument(ctx, 1, &arg); err != nil {
 		return nil, err
@@ -114,18 +149,24 @@ func (fn DeepMergeFunction) GetMergingOptions(ctx context.Context, args function
 	for idx, elem := range arg.Elements() {
 		val, err := helpers.EncodeValue(elem)
 		if err != nil {
-			return nil, function.NewArgumentFuncError(int64(idx), err.Error())
+			return nil, optionsArgumentError(idx, err)
 		}
 
-		if err := mapstructure.Decode(val, &opts); err != nil {
-			return nil, function.NewArgumentFuncError(int64(idx), err.Error())
+		if err := deepmerge.DecodeFunctionOptions(name, val, opts); err != nil {
+			return nil, optionsArgumentError(idx, err)
 		}
 	}
 
 	return opts, nil
 }
 
-func (fn DeepMergeFunction) FunctionResult(ctx context.Context, merged map[string]any) (basetypes.DynamicValue, diag.Diagnostics) {
-	value, diags := helpers.DecodeScalar(ctx, merged)
+// optionsArgumentError returns an error of the options object at the index, pointing at its position
+// among the variadic arguments following the objects argument.
+func optionsArgumentError(idx int, err error) *function.FuncError {
+	return function.NewArgumentFuncError(int64(1+idx), err.Error())
+}
+
+func (fn DeepMergeFunction) FunctionResult(ctx context.Context, merged map[string]any, hints []attr.Type) (basetypes.DynamicValue, diag.Diagnostics) {
+	value, diags := helpers.DecodeTyped(ctx, merged, hints)
 	return types.DynamicValue(value), diags
 }
@@ -78,6 +78,196 @@ func TestDeepMergeFunction_UnionLists(t *testing.T) {
 	})
 }
 
+func TestDeepMergeFunction_MergeKey(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_MergeKey(testdata.NewDeepMergeTestOptions()),
+	})
+}
+
+func TestDeepMergeFunction_Paths(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_Paths(testdata.NewDeepMergeTestOptions()),
+	})
+}
+
+func TestDeepMergeFunction_Directives(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_Directives(testdata.NewDeepMergeTestOptions()),
+	})
+}
+
+func TestDeepMergeFunction_OnConflict(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_OnConflict(testdata.NewDeepMergeTestOptions()),
+	})
+}
+
+func TestDeepMergeFunction_TypeMismatch(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_TypeMismatch(testdata.NewDeepMergeTestOptions()),
+	})
+}
+
+func TestDeepMergeFunction_NullDelete(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_NullDelete(testdata.NewDeepMergeTestOptions()),
+	})
+}
+
+func TestDeepMergeFunction_Atomic(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_Atomic(testdata.NewDeepMergeTestOptions()),
+	})
+}
+
+func TestDeepMergeFunction_PrependList(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_PrependList(testdata.NewDeepMergeTestOptions()),
+	})
+}
+
+func TestDeepMergeFunction_MergeByIndex(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_MergeByIndex(testdata.NewDeepMergeTestOptions()),
+	})
+}
+
+func TestDeepMergeFunction_UnionBy(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_UnionBy(testdata.NewDeepMergeTestOptions()),
+	})
+}
+
+func TestDeepMergeFunction_SortLists(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_SortLists(testdata.NewDeepMergeTestOptions()),
+	})
+}
+
+func TestDeepMergeFunction_Options(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_Options(testdata.NewDeepMergeTestOptions()),
+	})
+}
+
+func TestDeepMergeFunction_Profiles(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_Profiles(testdata.NewDeepMergeTestOptions()),
+	})
+}
+
+func TestDeepMergeFunction_PathExpressions(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_PathExpressions(testdata.NewDeepMergeTestOptions()),
+	})
+}
+
+func TestDeepMergeFunction_LargeNumbers(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_LargeNumbers(testdata.NewDeepMergeTestOptions()),
+	})
+}
+
+func TestDeepMergeFunction_CollectionTypes(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_CollectionTypes(testdata.NewDeepMergeTestOptions()),
+	})
+}
+
+func TestDeepMergeFunction_Sets(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_Sets(testdata.NewDeepMergeTestOptions()),
+	})
+}
+
+func TestDeepMergeFunction_UnknownValues(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_UnknownValues(testdata.NewDeepMergeTestOptions()),
+	})
+}
+
+func TestDeepMergeFunction_TypedNulls(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_TypedNulls(testdata.NewDeepMergeTestOptions()),
+	})
+}
+
 func TestDeepMergeFunction_Null(t *testing.T) {
 	resource.UnitTest(t, resource.TestCase{
 		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
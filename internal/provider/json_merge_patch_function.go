@@ -0,0 +1,86 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	_ "embed"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lablabs/terraform-provider-lara-utils/internal/helpers"
+	"github.com/lablabs/terraform-provider-lara-utils/internal/jsonpatch"
+)
+
+var (
+	_ function.Function = JsonMergePatchFunction{}
+	//go:embed json_merge_patch_function.md
+	jsonMergePatchFunctionDescription string
+)
+
+type JsonMergePatchFunction struct{}
+
+func NewJsonMergePatchFunction() function.Function {
+	return JsonMergePatchFunction{}
+}
+
+func (fn JsonMergePatchFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "json_merge_patch"
+}
+
+func (fn JsonMergePatchFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Apply RFC 7386 JSON Merge Patch",
+		MarkdownDescription: jsonMergePatchFunctionDescription,
+		Parameters: []function.Parameter{
+			function.DynamicParameter{
+				Name:                "target",
+				MarkdownDescription: "Value to patch",
+				AllowNullValue:      true,
+				AllowUnknownValues:  false,
+			},
+			function.DynamicParameter{
+				Name:                "patch",
+				MarkdownDescription: "Merge patch to apply",
+				AllowNullValue:      true,
+				AllowUnknownValues:  false,
+			},
+		},
+		Return: function.DynamicReturn{},
+	}
+}
+
+func (fn JsonMergePatchFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var target, patch types.Dynamic
+	if resp.Error = req.Arguments.Get(ctx, &target, &patch); resp.Error != nil {
+		return
+	}
+
+	targetVal, err := helpers.EncodeValue(target)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	patchVal, err := helpers.EncodeValue(patch)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, err.Error())
+		return
+	}
+
+	patched := jsonpatch.MergePatch(targetVal, patchVal)
+	if patched == nil {
+		resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, types.DynamicNull()))
+		return
+	}
+
+	value, diags := helpers.DecodeScalar(ctx, patched)
+	if diags.HasError() {
+		resp.Error = function.FuncErrorFromDiags(ctx, diags)
+		return
+	}
+
+	result := types.DynamicValue(value)
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, &result))
+}
@@ -0,0 +1,118 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestJsonMergePatchFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					locals {
+						target = {
+							title  = "Goodbye!"
+							author = {
+								givenName  = "John"
+								familyName = "Doe"
+							}
+							tags    = ["example", "sample"]
+							content = "This will be unchanged"
+						}
+						patch = {
+							title  = "Hello!"
+							phoneNumber = "+01-123-456-7890"
+							author = {
+								familyName = null
+							}
+							tags = ["example"]
+						}
+					}
+					output "test" {
+						value = provider::lara-utils::json_merge_patch(local.target, local.patch)
+					}
+				`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test",
+						knownvalue.MapExact(map[string]knownvalue.Check{
+							"title":       knownvalue.StringExact("Hello!"),
+							"phoneNumber": knownvalue.StringExact("+01-123-456-7890"),
+							"author": knownvalue.MapExact(map[string]knownvalue.Check{
+								"givenName": knownvalue.StringExact("John"),
+							}),
+							"tags": knownvalue.ListExact([]knownvalue.Check{
+								knownvalue.StringExact("example"),
+							}),
+							"content": knownvalue.StringExact("This will be unchanged"),
+						}),
+					),
+				},
+			},
+			{
+				Config: `
+					output "test" {
+						value = provider::lara-utils::json_merge_patch({ a = [{ b = "c" }] }, { a = [1], d = null })
+					}
+				`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test",
+						knownvalue.MapExact(map[string]knownvalue.Check{
+							"a": knownvalue.ListExact([]knownvalue.Check{
+								knownvalue.Int64Exact(1),
+							}),
+						}),
+					),
+				},
+			},
+			{
+				Config: `
+					output "test" {
+						value = provider::lara-utils::json_merge_patch(null, { a = { b = null, c = "d" } })
+					}
+				`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test",
+						knownvalue.MapExact(map[string]knownvalue.Check{
+							"a": knownvalue.MapExact(map[string]knownvalue.Check{
+								"c": knownvalue.StringExact("d"),
+							}),
+						}),
+					),
+				},
+			},
+			{
+				Config: `
+					output "test" {
+						value = provider::lara-utils::json_merge_patch({ a = "b" }, "c")
+					}
+				`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.StringExact("c")),
+				},
+			},
+			{
+				Config: `
+					output "test" {
+						value = provider::lara-utils::json_merge_patch({ a = "b" }, null) == null
+					}
+				`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.Bool(true)),
+				},
+			},
+		},
+	})
+}
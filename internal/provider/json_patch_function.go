@@ -0,0 +1,97 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	_ "embed"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lablabs/terraform-provider-lara-utils/internal/helpers"
+	"github.com/lablabs/terraform-provider-lara-utils/internal/jsonpatch"
+)
+
+var (
+	_ function.Function = JsonPatchFunction{}
+	//go:embed json_patch_function.md
+	jsonPatchFunctionDescription string
+)
+
+type JsonPatchFunction struct{}
+
+func NewJsonPatchFunction() function.Function {
+	return JsonPatchFunction{}
+}
+
+func (fn JsonPatchFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "json_patch"
+}
+
+func (fn JsonPatchFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Apply RFC 6902 JSON Patch",
+		MarkdownDescription: jsonPatchFunctionDescription,
+		Parameters: []function.Parameter{
+			function.DynamicParameter{
+				Name:                "document",
+				MarkdownDescription: "Value to patch",
+				AllowNullValue:      true,
+				AllowUnknownValues:  false,
+			},
+			function.DynamicParameter{
+				Name:                "operations",
+				MarkdownDescription: "List of patch operations to apply",
+				AllowNullValue:      false,
+				AllowUnknownValues:  false,
+			},
+		},
+		Return: function.DynamicReturn{},
+	}
+}
+
+func (fn JsonPatchFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var document, operations types.Dynamic
+	if resp.Error = req.Arguments.Get(ctx, &document, &operations); resp.Error != nil {
+		return
+	}
+
+	doc, err := helpers.EncodeValue(document)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	opsVal, err := helpers.EncodeValue(operations)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, err.Error())
+		return
+	}
+
+	ops, err := jsonpatch.DecodeOperations(opsVal)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, err.Error())
+		return
+	}
+
+	patched, err := jsonpatch.Apply(doc, ops)
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+
+	if patched == nil {
+		resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, types.DynamicNull()))
+		return
+	}
+
+	value, diags := helpers.DecodeScalar(ctx, patched)
+	if diags.HasError() {
+		resp.Error = function.FuncErrorFromDiags(ctx, diags)
+		return
+	}
+
+	result := types.DynamicValue(value)
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, &result))
+}
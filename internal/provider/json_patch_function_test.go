@@ -0,0 +1,111 @@
+// Copyright (c) Labyrinth Labs s.r.o.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestJsonPatchFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					locals {
+						manifest = {
+							metadata = {
+								name        = "app"
+								annotations = { "example.com/owner" = "vendor" }
+							}
+							spec = {
+								args = ["--port=8080", "--verbose", "--metrics"]
+							}
+						}
+					}
+					output "test" {
+						value = provider::lara-utils::json_patch(local.manifest, [
+							{ op = "test", path = "/metadata/name", value = "app" },
+							{ op = "add", path = "/spec/args/2", value = "--log-format=json" },
+							{ op = "remove", path = "/spec/args/1" },
+							{ op = "replace", path = "/metadata/annotations/example.com~1owner", value = "platform" },
+							{ op = "copy", from = "/metadata/name", path = "/spec/name" },
+							{ op = "move", from = "/spec/args/0", path = "/spec/args/-" },
+						])
+					}
+				`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test",
+						knownvalue.MapExact(map[string]knownvalue.Check{
+							"metadata": knownvalue.MapExact(map[string]knownvalue.Check{
+								"name": knownvalue.StringExact("app"),
+								"annotations": knownvalue.MapExact(map[string]knownvalue.Check{
+									"example.com/owner": knownvalue.StringExact("platform"),
+								}),
+							}),
+							"spec": knownvalue.MapExact(map[string]knownvalue.Check{
+								"name": knownvalue.StringExact("app"),
+								"args": knownvalue.ListExact([]knownvalue.Check{
+									knownvalue.StringExact("--log-format=json"),
+									knownvalue.StringExact("--metrics"),
+									knownvalue.StringExact("--port=8080"),
+								}),
+							}),
+						}),
+					),
+				},
+			},
+			{
+				Config: `
+					output "test" {
+						value = provider::lara-utils::json_patch(["a", "c"], [{ op = "add", path = "/1", value = "b" }])
+					}
+				`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test",
+						knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.StringExact("a"),
+							knownvalue.StringExact("b"),
+							knownvalue.StringExact("c"),
+						}),
+					),
+				},
+			},
+			{
+				Config: `
+					output "test" {
+						value = provider::lara-utils::json_patch({ name = "app" }, [{ op = "test", path = "/name", value = "db" }])
+					}
+				`,
+				ExpectError: regexp.MustCompile(`test failed at "/name"`),
+			},
+			{
+				Config: `
+					output "test" {
+						value = provider::lara-utils::json_patch({ name = "app" }, [{ op = "merge", path = "/name" }])
+					}
+				`,
+				ExpectError: regexp.MustCompile(`Invalid value for "operations" parameter: operation 1 has unsupported op`),
+			},
+			{
+				Config: `
+					output "test" {
+						value = provider::lara-utils::json_patch({ name = "app" }, null)
+					}
+				`,
+				ExpectError: regexp.MustCompile(`Invalid value for "operations" parameter: argument must not be null.`),
+			},
+		},
+	})
+}
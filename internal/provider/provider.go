@@ -52,6 +52,9 @@ func (p *LaraUtilsProvider) Functions(ctx context.Context) []func() function.Fun
 	return []func() function.Function{
 		NewDeepMergeFunction,
 		NewYamlDeepMergeFunction,
+		NewDeepMergeExplainFunction,
+		NewJsonMergePatchFunction,
+		NewJsonPatchFunction,
 	}
 }
 
@@ -5,11 +5,14 @@ package testdata
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
 	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
 )
 
 type DeepMergeTestConfig struct {
@@ -1479,3 +1482,1439 @@ func TestDeepMergeFunction_UnionLists(cfg DeepMergeTestConfig) []resource.TestSt
 		},
 	}
 }
+
+func TestDeepMergeFunction_MergeKey(cfg DeepMergeTestConfig) []resource.TestStep {
+	return []resource.TestStep{
+		{
+			Config: `
+					locals {
+						base = {
+							containers = [
+								{
+									name  = "app"
+									image = "app:1.0.0"
+									env   = [
+										{ name = "LOG_LEVEL", value = "info" }
+									]
+								},
+								{ name = "sidecar", image = "proxy:1.0.0" }
+							]
+						}
+						overlay = {
+							containers = [
+								{
+									name  = "app"
+									image = "app:1.1.0"
+									env   = [
+										{ name = "DEBUG", value = "true" },
+										{ name = "LOG_LEVEL", value = "debug" }
+									]
+								},
+								{ name = "exporter", image = "exporter:1.0.0" }
+							]
+							tags = ["overlay"]
+						}
+					}
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.base", "local.overlay"}, `{ merge_key = "name" }`) + `
+					}
+				`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"containers": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"name":  knownvalue.StringExact("app"),
+								"image": knownvalue.StringExact("app:1.1.0"),
+								"env": knownvalue.ListExact([]knownvalue.Check{
+									knownvalue.MapExact(map[string]knownvalue.Check{
+										"name":  knownvalue.StringExact("LOG_LEVEL"),
+										"value": knownvalue.StringExact("debug"),
+									}),
+									knownvalue.MapExact(map[string]knownvalue.Check{
+										"name":  knownvalue.StringExact("DEBUG"),
+										"value": knownvalue.StringExact("true"),
+									}),
+								}),
+							}),
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"name":  knownvalue.StringExact("sidecar"),
+								"image": knownvalue.StringExact("proxy:1.0.0"),
+							}),
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"name":  knownvalue.StringExact("exporter"),
+								"image": knownvalue.StringExact("exporter:1.0.0"),
+							}),
+						}),
+						"tags": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.StringExact("overlay"),
+						}),
+					}),
+				),
+			},
+		},
+		{
+			Config: `
+					locals {
+						base = {
+							ports = [
+								{ protocol = "TCP", port = 80, name = "http" },
+								{ protocol = "TCP", port = 443, name = "https" }
+							]
+						}
+						overlay = {
+							ports = [
+								{ protocol = "UDP", port = 80, name = "quic" },
+								{ protocol = "TCP", port = 443, name = "tls" }
+							]
+						}
+					}
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.base", "local.overlay"}, `{ merge_key = ["protocol", "port"] }`) + `
+					}
+				`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"ports": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"protocol": knownvalue.StringExact("TCP"),
+								"port":     knownvalue.Int64Exact(80),
+								"name":     knownvalue.StringExact("http"),
+							}),
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"protocol": knownvalue.StringExact("TCP"),
+								"port":     knownvalue.Int64Exact(443),
+								"name":     knownvalue.StringExact("tls"),
+							}),
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"protocol": knownvalue.StringExact("UDP"),
+								"port":     knownvalue.Int64Exact(80),
+								"name":     knownvalue.StringExact("quic"),
+							}),
+						}),
+					}),
+				),
+			},
+		},
+	}
+}
+
+func TestDeepMergeFunction_Paths(cfg DeepMergeTestConfig) []resource.TestStep {
+	return []resource.TestStep{
+		{
+			Config: `
+					locals {
+						map1 = {
+							extraEnv    = ["A"]
+							tolerations = ["dedicated", "gpu"]
+							args        = ["--verbose"]
+							sidecar = {
+								extraEnv = ["B"]
+								image    = "proxy:1.0.0"
+							}
+						}
+						map2 = {
+							extraEnv    = ["C"]
+							tolerations = ["gpu", "spot"]
+							args        = ["--quiet"]
+							sidecar = {
+								extraEnv = ["D"]
+								image    = "proxy:1.1.0"
+							}
+						}
+					}
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.map1", "local.map2"}, `{
+							paths = {
+								"extraEnv"    = { append_list = true }
+								"tolerations" = { union_lists = true }
+								"*.extraEnv"  = { append_list = true }
+								"sidecar"     = { override = false }
+							}
+						}`) + `
+					}
+				`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"extraEnv": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.StringExact("A"),
+							knownvalue.StringExact("C"),
+						}),
+						"tolerations": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.StringExact("dedicated"),
+							knownvalue.StringExact("gpu"),
+							knownvalue.StringExact("spot"),
+						}),
+						"args": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.StringExact("--quiet"),
+						}),
+						"sidecar": knownvalue.MapExact(map[string]knownvalue.Check{
+							"extraEnv": knownvalue.ListExact([]knownvalue.Check{
+								knownvalue.StringExact("B"),
+								knownvalue.StringExact("D"),
+							}),
+							"image": knownvalue.StringExact("proxy:1.0.0"),
+						}),
+					}),
+				),
+			},
+		},
+		{
+			Config: `
+					locals {
+						map1 = {
+							items = [
+								{ tags = ["a"] },
+								{ tags = ["b"] }
+							]
+						}
+						map2 = {
+							items = [
+								{ tags = ["c"] },
+								{ tags = ["d"] }
+							]
+						}
+					}
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.map1", "local.map2"}, `{
+							paths = {
+								"items"          = { deep_copy_list = true }
+								"items[1].tags"  = { append_list = true }
+							}
+						}`) + `
+					}
+				`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"items": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"tags": knownvalue.ListExact([]knownvalue.Check{
+									knownvalue.StringExact("c"),
+								}),
+							}),
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"tags": knownvalue.ListExact([]knownvalue.Check{
+									knownvalue.StringExact("b"),
+									knownvalue.StringExact("d"),
+								}),
+							}),
+						}),
+					}),
+				),
+			},
+		},
+		{
+			Config: `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"{ a = 1 }"}, `{ paths = { "a..b" = { append_list = true } } }`) + `
+					}
+				`,
+			ExpectError: regexp.MustCompile(`invalid path "a..b"`),
+		},
+	}
+}
+
+func TestDeepMergeFunction_Directives(cfg DeepMergeTestConfig) []resource.TestStep {
+	return []resource.TestStep{
+		{
+			Config: `
+					locals {
+						base = {
+							affinity = {
+								nodeAffinity = { zone = "a" }
+							}
+							obsolete = {
+								enabled = true
+							}
+							securityContext = {
+								runAsUser  = 1000
+								runAsGroup = 1000
+								fsGroup    = 1000
+							}
+							finalizers = ["cleanup", "protect"]
+							args       = ["--a", "--b"]
+							containers = [
+								{ name = "app", image = "app:1.0.0" },
+								{ name = "debug", image = "busybox" },
+								{ name = "proxy", image = "proxy:1.0.0", port = 8080 }
+							]
+						}
+						overlay = {
+							affinity = {
+								"$patch" = "replace"
+								podAntiAffinity = { host = true }
+							}
+							obsolete = {
+								"$patch" = "delete"
+							}
+							securityContext = {
+								"$retainKeys" = ["runAsUser", "runAsNonRoot"]
+								runAsNonRoot  = true
+							}
+							args = [{ "$patch" = "replace" }, "--c"]
+							containers = [
+								{ name = "debug", "$patch" = "delete" },
+								{ name = "proxy", "$patch" = "replace", image = "proxy:2.0.0" }
+							]
+							"$deleteFromPrimitiveList/finalizers" = ["protect"]
+							"$setElementOrder/containers" = [{ name = "proxy" }, { name = "app" }]
+						}
+					}
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.base", "local.overlay"}, `{ merge_key = "name" }`) + `
+					}
+				`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"affinity": knownvalue.MapExact(map[string]knownvalue.Check{
+							"podAntiAffinity": knownvalue.MapExact(map[string]knownvalue.Check{
+								"host": knownvalue.Bool(true),
+							}),
+						}),
+						"securityContext": knownvalue.MapExact(map[string]knownvalue.Check{
+							"runAsUser":    knownvalue.Int64Exact(1000),
+							"runAsNonRoot": knownvalue.Bool(true),
+						}),
+						"finalizers": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.StringExact("cleanup"),
+						}),
+						"args": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.StringExact("--c"),
+						}),
+						"containers": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"name":  knownvalue.StringExact("proxy"),
+								"image": knownvalue.StringExact("proxy:2.0.0"),
+							}),
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"name":  knownvalue.StringExact("app"),
+								"image": knownvalue.StringExact("app:1.0.0"),
+							}),
+						}),
+					}),
+				),
+			},
+		},
+		{
+			Config: `
+					locals {
+						base = {
+							config = { "$patch" = "keep" }
+						}
+					}
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.base"}, `{}`) + `
+					}
+				`,
+			ExpectError: regexp.MustCompile(`invalid directive "\$patch"`),
+		},
+		{
+			Config: `
+					locals {
+						base = {
+							config = { "$patch" = "delete", enabled = true }
+						}
+					}
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.base"}, `{ directives = false }`) + `
+					}
+				`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"config": knownvalue.MapExact(map[string]knownvalue.Check{
+							"$patch":  knownvalue.StringExact("delete"),
+							"enabled": knownvalue.Bool(true),
+						}),
+					}),
+				),
+			},
+		},
+	}
+}
+
+func TestDeepMergeFunction_OnConflict(cfg DeepMergeTestConfig) []resource.TestStep {
+	locals := `
+		locals {
+			baseline = {
+				security = { encryption = true, audit = true }
+				replicas = 2
+				tags     = ["baseline"]
+			}
+			team = {
+				security = { encryption = false, audit = true }
+				replicas = 3
+				tags     = ["team"]
+			}
+		}
+	`
+
+	return []resource.TestStep{
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.baseline", "local.team"}, `{ on_conflict = "keep_first", append_list = true }`) + `
+					}
+				`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"security": knownvalue.MapExact(map[string]knownvalue.Check{
+							"encryption": knownvalue.Bool(true),
+							"audit":      knownvalue.Bool(true),
+						}),
+						"replicas": knownvalue.Int64Exact(2),
+						"tags": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.StringExact("baseline"),
+							knownvalue.StringExact("team"),
+						}),
+					}),
+				),
+			},
+		},
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.baseline", "local.team"}, `{ paths = { "security.*" = { on_conflict = "error" } } }`) + `
+					}
+				`,
+			ExpectError: regexp.MustCompile(`security.encryption: set differently by arguments 1 and 2`),
+		},
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.baseline", "{}", "local.team"}, `{ on_conflict = "error" }`) + `
+					}
+				`,
+			ExpectError: regexp.MustCompile(`replicas: set differently by arguments 1 and 3`),
+		},
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.baseline", "local.team"}, `{ on_conflict = "fail" }`) + `
+					}
+				`,
+			ExpectError: regexp.MustCompile(`on_conflict must be one of`),
+		},
+	}
+}
+
+func TestDeepMergeFunction_TypeMismatch(cfg DeepMergeTestConfig) []resource.TestStep {
+	locals := `
+		locals {
+			base = {
+				ingress  = { enabled = true }
+				ports    = [80, 443]
+				replicas = 2
+			}
+			overlay = {
+				ingress  = "enabled"
+				ports    = { http = 80 }
+				replicas = 3
+			}
+		}
+	`
+
+	return []resource.TestStep{
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.base", "local.overlay"}, `{ type_mismatch = "replace" }`) + `
+					}
+				`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"ingress": knownvalue.StringExact("enabled"),
+						"ports": knownvalue.MapExact(map[string]knownvalue.Check{
+							"http": knownvalue.Int64Exact(80),
+						}),
+						"replicas": knownvalue.Int64Exact(3),
+					}),
+				),
+			},
+		},
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.base", "local.overlay"}, `{ type_mismatch = "keep" }`) + `
+					}
+				`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"ingress": knownvalue.MapExact(map[string]knownvalue.Check{
+							"enabled": knownvalue.Bool(true),
+						}),
+						"ports": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.Int64Exact(80),
+							knownvalue.Int64Exact(443),
+						}),
+						"replicas": knownvalue.Int64Exact(3),
+					}),
+				),
+			},
+		},
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.base", "local.overlay"}, `{ type_mismatch = "error" }`) + `
+					}
+				`,
+			ExpectError: regexp.MustCompile(`ingress: object set by argument 1, string set by argument 2`),
+		},
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.base", "local.overlay"}, `{ type_mismatch = "fail" }`) + `
+					}
+				`,
+			ExpectError: regexp.MustCompile(`type_mismatch must be one of`),
+		},
+	}
+}
+
+func TestDeepMergeFunction_NullDelete(cfg DeepMergeTestConfig) []resource.TestStep {
+	locals := `
+		locals {
+			base = {
+				name             = "service"
+				optional_setting = "enabled"
+				resources        = { limits = { cpu = "1", memory = "1Gi" } }
+			}
+			overrides = {
+				optional_setting = null
+				resources        = { limits = { cpu = null } }
+				extra            = { enabled = true, comment = null, args = ["--a", null] }
+			}
+		}
+	`
+
+	return []resource.TestStep{
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.base", "local.overrides"}, `{ null_delete = true }`) + `
+					}
+				`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"name": knownvalue.StringExact("service"),
+						"resources": knownvalue.MapExact(map[string]knownvalue.Check{
+							"limits": knownvalue.MapExact(map[string]knownvalue.Check{
+								"memory": knownvalue.StringExact("1Gi"),
+							}),
+						}),
+						"extra": knownvalue.MapExact(map[string]knownvalue.Check{
+							"enabled": knownvalue.Bool(true),
+							"args": knownvalue.ListExact([]knownvalue.Check{
+								knownvalue.StringExact("--a"),
+								knownvalue.Null(),
+							}),
+						}),
+					}),
+				),
+			},
+		},
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.base", "local.overrides"}, `{ null_delete = true, null_delete_elements = true }`) + `
+					}
+				`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapPartial(map[string]knownvalue.Check{
+						"extra": knownvalue.MapExact(map[string]knownvalue.Check{
+							"enabled": knownvalue.Bool(true),
+							"args": knownvalue.ListExact([]knownvalue.Check{
+								knownvalue.StringExact("--a"),
+							}),
+						}),
+					}),
+				),
+			},
+		},
+	}
+}
+
+func TestDeepMergeFunction_Atomic(cfg DeepMergeTestConfig) []resource.TestStep {
+	locals := `
+		locals {
+			base = {
+				affinity   = { nodeAffinity = { zone = "a" } }
+				resources  = { limits = { cpu = "1" } }
+				containers = [{ name = "app", securityContext = { runAsUser = 1000, runAsGroup = 1000 } }]
+			}
+			overlay = {
+				affinity   = { podAntiAffinity = { host = true } }
+				resources  = { limits = { memory = "1Gi" } }
+				containers = [{ name = "app", securityContext = { runAsNonRoot = true } }]
+			}
+		}
+	`
+
+	return []resource.TestStep{
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.base", "local.overlay"}, `{ atomic_paths = ["affinity", "containers[*].securityContext"], merge_key = "name" }`) + `
+					}
+				`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"affinity": knownvalue.MapExact(map[string]knownvalue.Check{
+							"podAntiAffinity": knownvalue.MapExact(map[string]knownvalue.Check{
+								"host": knownvalue.Bool(true),
+							}),
+						}),
+						"resources": knownvalue.MapExact(map[string]knownvalue.Check{
+							"limits": knownvalue.MapExact(map[string]knownvalue.Check{
+								"cpu":    knownvalue.StringExact("1"),
+								"memory": knownvalue.StringExact("1Gi"),
+							}),
+						}),
+						"containers": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"name": knownvalue.StringExact("app"),
+								"securityContext": knownvalue.MapExact(map[string]knownvalue.Check{
+									"runAsNonRoot": knownvalue.Bool(true),
+								}),
+							}),
+						}),
+					}),
+				),
+			},
+		},
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.base", "local.overlay"}, `{ max_depth = 2 }`) + `
+					}
+				`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapPartial(map[string]knownvalue.Check{
+						"affinity": knownvalue.MapExact(map[string]knownvalue.Check{
+							"nodeAffinity": knownvalue.MapExact(map[string]knownvalue.Check{
+								"zone": knownvalue.StringExact("a"),
+							}),
+							"podAntiAffinity": knownvalue.MapExact(map[string]knownvalue.Check{
+								"host": knownvalue.Bool(true),
+							}),
+						}),
+						"resources": knownvalue.MapExact(map[string]knownvalue.Check{
+							"limits": knownvalue.MapExact(map[string]knownvalue.Check{
+								"memory": knownvalue.StringExact("1Gi"),
+							}),
+						}),
+					}),
+				),
+			},
+		},
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.base", "local.overlay"}, `{ max_depth = -1 }`) + `
+					}
+				`,
+			ExpectError: regexp.MustCompile(`max_depth must not be negative`),
+		},
+	}
+}
+
+func TestDeepMergeFunction_PrependList(cfg DeepMergeTestConfig) []resource.TestStep {
+	locals := `
+		locals {
+			base = {
+				middlewares = ["auth", "compress"]
+				rules       = [{ port = 22 }]
+			}
+			overlay = {
+				middlewares = ["ratelimit", "auth"]
+				rules       = [{ port = 443 }]
+			}
+		}
+	`
+
+	return []resource.TestStep{
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.base", "local.overlay"}, `{ prepend_list = true }`) + `
+					}
+				`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"middlewares": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.StringExact("ratelimit"),
+							knownvalue.StringExact("auth"),
+							knownvalue.StringExact("auth"),
+							knownvalue.StringExact("compress"),
+						}),
+						"rules": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.MapExact(map[string]knownvalue.Check{"port": knownvalue.Int64Exact(443)}),
+							knownvalue.MapExact(map[string]knownvalue.Check{"port": knownvalue.Int64Exact(22)}),
+						}),
+					}),
+				),
+			},
+		},
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.base", "local.overlay"}, `{ prepend_union = true, paths = { rules = { prepend_union = false, append_list = true } } }`) + `
+					}
+				`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"middlewares": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.StringExact("ratelimit"),
+							knownvalue.StringExact("auth"),
+							knownvalue.StringExact("compress"),
+						}),
+						"rules": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.MapExact(map[string]knownvalue.Check{"port": knownvalue.Int64Exact(22)}),
+							knownvalue.MapExact(map[string]knownvalue.Check{"port": knownvalue.Int64Exact(443)}),
+						}),
+					}),
+				),
+			},
+		},
+	}
+}
+
+func TestDeepMergeFunction_MergeByIndex(cfg DeepMergeTestConfig) []resource.TestStep {
+	return []resource.TestStep{
+		{
+			Config: `
+					locals {
+						base = {
+							ports = [{ name = "http", port = 80 }, { name = "https", port = 443 }]
+							args  = ["--a", "--b", "--c"]
+						}
+						overlay = {
+							ports = [null, { port = 8443 }, { name = "metrics", port = 9090 }]
+							args  = [null, "--x"]
+						}
+					}
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.base", "local.overlay"}, `{ merge_by_index = true }`) + `
+					}
+				`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"ports": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"name": knownvalue.StringExact("http"),
+								"port": knownvalue.Int64Exact(80),
+							}),
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"name": knownvalue.StringExact("https"),
+								"port": knownvalue.Int64Exact(8443),
+							}),
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"name": knownvalue.StringExact("metrics"),
+								"port": knownvalue.Int64Exact(9090),
+							}),
+						}),
+						"args": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.StringExact("--a"),
+							knownvalue.StringExact("--x"),
+							knownvalue.StringExact("--c"),
+						}),
+					}),
+				),
+			},
+		},
+	}
+}
+
+func TestDeepMergeFunction_UnionBy(cfg DeepMergeTestConfig) []resource.TestStep {
+	locals := `
+		locals {
+			base = {
+				ingress = [
+					{ protocol = "tcp", port = 22, description = "ssh", cidrs = ["10.0.0.0/8"] },
+					{ protocol = "tcp", port = 443, description = "https" },
+				]
+			}
+			overlay = {
+				ingress = [
+					{ protocol = "tcp", port = 22, description = "SSH from VPN" },
+					{ protocol = "udp", port = 443, description = "quic" },
+				]
+			}
+		}
+	`
+
+	return []resource.TestStep{
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.base", "local.overlay"}, `{ union_by = ["protocol", "port"] }`) + `
+					}
+				`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"ingress": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"protocol":    knownvalue.StringExact("tcp"),
+								"port":        knownvalue.Int64Exact(22),
+								"description": knownvalue.StringExact("SSH from VPN"),
+							}),
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"protocol":    knownvalue.StringExact("tcp"),
+								"port":        knownvalue.Int64Exact(443),
+								"description": knownvalue.StringExact("https"),
+							}),
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"protocol":    knownvalue.StringExact("udp"),
+								"port":        knownvalue.Int64Exact(443),
+								"description": knownvalue.StringExact("quic"),
+							}),
+						}),
+					}),
+				),
+			},
+		},
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.base", "local.overlay"}, `{ union_by = ["protocol", "port"], union_by_strategy = "merge" }`) + `
+					}
+				`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"ingress": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"protocol":    knownvalue.StringExact("tcp"),
+								"port":        knownvalue.Int64Exact(22),
+								"description": knownvalue.StringExact("SSH from VPN"),
+								"cidrs": knownvalue.ListExact([]knownvalue.Check{
+									knownvalue.StringExact("10.0.0.0/8"),
+								}),
+							}),
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"protocol":    knownvalue.StringExact("tcp"),
+								"port":        knownvalue.Int64Exact(443),
+								"description": knownvalue.StringExact("https"),
+							}),
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"protocol":    knownvalue.StringExact("udp"),
+								"port":        knownvalue.Int64Exact(443),
+								"description": knownvalue.StringExact("quic"),
+							}),
+						}),
+					}),
+				),
+			},
+		},
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.base", "local.overlay"}, `{ union_by = ["protocol"], union_by_strategy = "first" }`) + `
+					}
+				`,
+			ExpectError: regexp.MustCompile(`union_by_strategy must be one of`),
+		},
+	}
+}
+
+func TestDeepMergeFunction_SortLists(cfg DeepMergeTestConfig) []resource.TestStep {
+	locals := `
+		locals {
+			base = {
+				nodes = ["node10", "node2"]
+				ports = [443, 80]
+				users = [{ name = "jane" }, { name = "bob" }]
+			}
+			overlay = {
+				nodes = ["node1"]
+				ports = [8080]
+				users = [{ name = "alice" }]
+			}
+		}
+	`
+
+	return []resource.TestStep{
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.overlay", "local.base"}, `{ union_lists = true, sort_lists = true, sort_by = ["name"] }`) + `
+					}
+				`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"nodes": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.StringExact("node1"),
+							knownvalue.StringExact("node2"),
+							knownvalue.StringExact("node10"),
+						}),
+						"ports": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.Int64Exact(80),
+							knownvalue.Int64Exact(443),
+							knownvalue.Int64Exact(8080),
+						}),
+						"users": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.MapExact(map[string]knownvalue.Check{"name": knownvalue.StringExact("alice")}),
+							knownvalue.MapExact(map[string]knownvalue.Check{"name": knownvalue.StringExact("bob")}),
+							knownvalue.MapExact(map[string]knownvalue.Check{"name": knownvalue.StringExact("jane")}),
+						}),
+					}),
+				),
+			},
+		},
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.base", "local.overlay"}, `{ append_list = true, paths = { nodes = { sort_lists = true } } }`) + `
+					}
+				`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapPartial(map[string]knownvalue.Check{
+						"nodes": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.StringExact("node1"),
+							knownvalue.StringExact("node2"),
+							knownvalue.StringExact("node10"),
+						}),
+						"ports": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.Int64Exact(443),
+							knownvalue.Int64Exact(80),
+							knownvalue.Int64Exact(8080),
+						}),
+					}),
+				),
+			},
+		},
+	}
+}
+
+func TestDeepMergeFunction_Options(cfg DeepMergeTestConfig) []resource.TestStep {
+	return []resource.TestStep{
+		{
+			Config: `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"{ a = [1] }", "{ a = [2] }"}, `{ apend_list = true }`) + `
+					}
+				`,
+			ExpectError: regexp.MustCompile(`(?s)Invalid value for "options" parameter:.*unknown option "apend_list".*did you mean.*"append_list"`),
+		},
+		{
+			Config: `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"{ a = [1] }", "{ a = [2] }"}, `{ append_list = true }, { override = "yes" }`) + `
+					}
+				`,
+			ExpectError: regexp.MustCompile(`(?s)Invalid value for "options" parameter:.*override must be a bool`),
+		},
+		{
+			Config: `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"{ a = [1] }", "{ a = [2] }"}, `{ paths = { a = { union_list = true } } }`) + `
+					}
+				`,
+			ExpectError: regexp.MustCompile(`(?s)unknown option "union_list".*did you mean.*"union_lists"`),
+		},
+		{
+			Config: `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"{ a = [1] }", "{ a = [2] }"}, `{ on_conflict = "eror" }`) + `
+					}
+				`,
+			ExpectError: regexp.MustCompile(`(?s)did you mean.*"error"`),
+		},
+	}
+}
+
+func TestDeepMergeFunction_PathExpressions(cfg DeepMergeTestConfig) []resource.TestStep {
+	locals := `
+		locals {
+			base = {
+				"a.b"      = [1]
+				containers = [{ name = "app", args = ["a"] }, { name = "sidecar", args = ["x"] }]
+			}
+			overlay = {
+				"a.b"      = [2]
+				containers = [{ name = "app", args = ["b"] }, { name = "sidecar", args = ["y"] }]
+			}
+		}
+	`
+
+	return []resource.TestStep{
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.base", "local.overlay"}, `{
+							merge_key = "name"
+							paths = {
+								"\"a.b\""                     = { append_list = true }
+								"**.containers[name=app].args" = { append_list = true }
+							}
+						}`) + `
+					}
+				`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"a.b": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.Int64Exact(1),
+							knownvalue.Int64Exact(2),
+						}),
+						"containers": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"name": knownvalue.StringExact("app"),
+								"args": knownvalue.ListExact([]knownvalue.Check{
+									knownvalue.StringExact("a"),
+									knownvalue.StringExact("b"),
+								}),
+							}),
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"name": knownvalue.StringExact("sidecar"),
+								"args": knownvalue.ListExact([]knownvalue.Check{
+									knownvalue.StringExact("y"),
+								}),
+							}),
+						}),
+					}),
+				),
+			},
+		},
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.base", "local.overlay"}, `{ paths = { "containers[name=app" = { append_list = true } } }`) + `
+					}
+				`,
+			ExpectError: regexp.MustCompile(`(?s)invalid path.*unterminated list selector at position 11`),
+		},
+	}
+}
+
+func TestDeepMergeFunction_LargeNumbers(cfg DeepMergeTestConfig) []resource.TestStep {
+	return []resource.TestStep{
+		{
+			Config: `
+				locals {
+					base = {
+						account_id = 123456789012345678
+						ids        = [9007199254740993, 1]
+						ratio      = 0.1
+					}
+					overlay = {
+						ids = [9007199254740993, 9007199254740992]
+					}
+				}
+
+				output "test" {
+					value = ` + providerFunctionCall(cfg, []string{"local.base", "local.overlay"}, `{ union_lists = true }`) + `
+				}
+			`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"account_id": knownvalue.Int64Exact(123456789012345678),
+						"ids": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.Int64Exact(9007199254740993),
+							knownvalue.Int64Exact(1),
+							knownvalue.Int64Exact(9007199254740992),
+						}),
+						"ratio": knownvalue.Float64Exact(0.1),
+					}),
+				),
+			},
+		},
+	}
+}
+
+func TestDeepMergeFunction_CollectionTypes(cfg DeepMergeTestConfig) []resource.TestStep {
+	return []resource.TestStep{
+		{
+			Config: `
+				variable "layers" {
+					type = list(object({
+						labels = map(string)
+						zones  = list(string)
+						cidrs  = set(string)
+					}))
+					default = [
+						{
+							labels = { team = "platform", env = "dev" }
+							zones  = ["a", "b"]
+							cidrs  = ["10.0.0.0/16"]
+						},
+						{
+							labels = { env = "prod" }
+							zones  = ["c"]
+							cidrs  = ["10.1.0.0/16", "10.2.0.0/16"]
+						},
+					]
+				}
+
+				output "test" {
+					value = ` + providerFunctionCall(cfg, []string{"var.layers[0]", "var.layers[1]"}, "{}") + `
+				}
+			`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"labels": knownvalue.MapExact(map[string]knownvalue.Check{
+							"team": knownvalue.StringExact("platform"),
+							"env":  knownvalue.StringExact("prod"),
+						}),
+						"zones": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.StringExact("c"),
+						}),
+						"cidrs": knownvalue.SetExact([]knownvalue.Check{
+							knownvalue.StringExact("10.0.0.0/16"),
+							knownvalue.StringExact("10.1.0.0/16"),
+							knownvalue.StringExact("10.2.0.0/16"),
+						}),
+					}),
+				),
+			},
+		},
+		{
+			Config: `
+				variable "tags" {
+					type    = map(string)
+					default = { team = "platform" }
+				}
+
+				output "test" {
+					value = ` + providerFunctionCall(cfg, []string{"var.tags", "{}"}, "{}") + ` == var.tags
+				}
+			`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test", knownvalue.Bool(true)),
+			},
+		},
+	}
+}
+
+func TestDeepMergeFunction_Sets(cfg DeepMergeTestConfig) []resource.TestStep {
+	locals := `
+		variable "base" {
+			type = object({
+				cidrs = set(string)
+				rules = list(object({
+					name  = string
+					ports = set(number)
+				}))
+			})
+			default = {
+				cidrs = ["10.0.0.0/16", "10.1.0.0/16"]
+				rules = [{ name = "web", ports = [80] }]
+			}
+		}
+
+		locals {
+			overlay = {
+				cidrs = ["10.1.0.0/16", "10.2.0.0/16"]
+				rules = [{ name = "web", ports = [443, 80] }]
+			}
+		}
+	`
+
+	return []resource.TestStep{
+		{
+			Config: locals + `
+				output "test" {
+					value = ` + providerFunctionCall(cfg, []string{"var.base", "local.overlay"}, `{ merge_key = "name" }`) + `
+				}
+			`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"cidrs": knownvalue.SetExact([]knownvalue.Check{
+							knownvalue.StringExact("10.0.0.0/16"),
+							knownvalue.StringExact("10.1.0.0/16"),
+							knownvalue.StringExact("10.2.0.0/16"),
+						}),
+						"rules": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"name": knownvalue.StringExact("web"),
+								"ports": knownvalue.SetExact([]knownvalue.Check{
+									knownvalue.Int64Exact(80),
+									knownvalue.Int64Exact(443),
+								}),
+							}),
+						}),
+					}),
+				),
+			},
+		},
+		{
+			Config: locals + `
+				output "test" {
+					value = ` + providerFunctionCall(cfg, []string{"var.base", "local.overlay"}, `{ append_list = true }`) + `
+				}
+			`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"cidrs": knownvalue.SetExact([]knownvalue.Check{
+							knownvalue.StringExact("10.0.0.0/16"),
+							knownvalue.StringExact("10.1.0.0/16"),
+							knownvalue.StringExact("10.2.0.0/16"),
+						}),
+						"rules": knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"name": knownvalue.StringExact("web"),
+								"ports": knownvalue.SetExact([]knownvalue.Check{
+									knownvalue.Int64Exact(80),
+								}),
+							}),
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"name": knownvalue.StringExact("web"),
+								"ports": knownvalue.SetExact([]knownvalue.Check{
+									knownvalue.Int64Exact(80),
+									knownvalue.Int64Exact(443),
+								}),
+							}),
+						}),
+					}),
+				),
+			},
+		},
+	}
+}
+
+func TestDeepMergeFunction_UnknownValues(cfg DeepMergeTestConfig) []resource.TestStep {
+	return []resource.TestStep{
+		{
+			Config: `
+				resource "terraform_data" "role" {}
+
+				locals {
+					base = {
+						name   = "app"
+						labels = { team = "platform" }
+						tags   = ["a"]
+					}
+					overlay = {
+						role   = terraform_data.role.id
+						labels = { env = terraform_data.role.id }
+						tags   = ["b", terraform_data.role.id]
+					}
+				}
+
+				output "test" {
+					value = ` + providerFunctionCall(cfg, []string{"local.base", "local.overlay"}, `{ union_lists = true }`) + `
+				}
+			`,
+			ConfigPlanChecks: resource.ConfigPlanChecks{
+				PreApply: []plancheck.PlanCheck{
+					plancheck.ExpectKnownOutputValueAtPath("test", tfjsonpath.New("name"), knownvalue.StringExact("app")),
+					plancheck.ExpectKnownOutputValueAtPath("test", tfjsonpath.New("labels").AtMapKey("team"), knownvalue.StringExact("platform")),
+					plancheck.ExpectUnknownOutputValueAtPath("test", tfjsonpath.New("labels").AtMapKey("env")),
+					plancheck.ExpectUnknownOutputValueAtPath("test", tfjsonpath.New("role")),
+					plancheck.ExpectUnknownOutputValueAtPath("test", tfjsonpath.New("tags")),
+				},
+			},
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"name": knownvalue.StringExact("app"),
+						"role": knownvalue.NotNull(),
+						"labels": knownvalue.MapExact(map[string]knownvalue.Check{
+							"team": knownvalue.StringExact("platform"),
+							"env":  knownvalue.NotNull(),
+						}),
+						"tags": knownvalue.ListSizeExact(3),
+					}),
+				),
+			},
+		},
+	}
+}
+
+func TestDeepMergeFunction_TypedNulls(cfg DeepMergeTestConfig) []resource.TestStep {
+	return []resource.TestStep{
+		{
+			Config: `
+				variable "base" {
+					type = object({
+						replicas = number
+						labels   = map(string)
+					})
+					default = {
+						replicas = 2
+						labels   = { team = "platform", env = null }
+					}
+				}
+
+				variable "overlay" {
+					type = object({
+						labels = map(string)
+					})
+					default = {
+						labels = { owner = "ops" }
+					}
+				}
+
+				output "test" {
+					value = ` + providerFunctionCall(cfg, []string{"var.base", "var.overlay", "{ replicas = null }"}, "{}") + `
+				}
+			`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"replicas": knownvalue.Null(),
+						"labels": knownvalue.MapExact(map[string]knownvalue.Check{
+							"team":  knownvalue.StringExact("platform"),
+							"env":   knownvalue.Null(),
+							"owner": knownvalue.StringExact("ops"),
+						}),
+					}),
+				),
+			},
+		},
+	}
+}
+
+func TestDeepMergeFunction_Profiles(cfg DeepMergeTestConfig) []resource.TestStep {
+	locals := `
+		locals {
+			deployment = {
+				spec = {
+					replicas = 2
+					template = {
+						spec = {
+							containers = [
+								{ name = "app", image = "app:1.0", env = [{ name = "LOG_LEVEL", value = "info" }] },
+								{ name = "proxy", image = "envoy:1.30" },
+							]
+						}
+					}
+				}
+			}
+			patch = {
+				spec = {
+					replicas = null
+					template = {
+						spec = {
+							containers = [
+								{ name = "app", image = "app:1.1", env = [{ name = "DEBUG", value = "true" }] },
+							]
+						}
+					}
+				}
+			}
+		}
+	`
+
+	return []resource.TestStep{
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.deployment", "local.patch"}, `{ profile = "kubernetes" }`) + `
+					}
+				`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"spec": knownvalue.MapExact(map[string]knownvalue.Check{
+							"template": knownvalue.MapExact(map[string]knownvalue.Check{
+								"spec": knownvalue.MapExact(map[string]knownvalue.Check{
+									"containers": knownvalue.ListExact([]knownvalue.Check{
+										knownvalue.MapExact(map[string]knownvalue.Check{
+											"name":  knownvalue.StringExact("app"),
+											"image": knownvalue.StringExact("app:1.1"),
+											"env": knownvalue.ListExact([]knownvalue.Check{
+												knownvalue.MapExact(map[string]knownvalue.Check{
+													"name":  knownvalue.StringExact("LOG_LEVEL"),
+													"value": knownvalue.StringExact("info"),
+												}),
+												knownvalue.MapExact(map[string]knownvalue.Check{
+													"name":  knownvalue.StringExact("DEBUG"),
+													"value": knownvalue.StringExact("true"),
+												}),
+											}),
+										}),
+										knownvalue.MapExact(map[string]knownvalue.Check{
+											"name":  knownvalue.StringExact("proxy"),
+											"image": knownvalue.StringExact("envoy:1.30"),
+										}),
+									}),
+								}),
+							}),
+						}),
+					}),
+				),
+			},
+		},
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.deployment", "local.patch"}, `{ profile = "helm" }`) + `
+					}
+				`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapExact(map[string]knownvalue.Check{
+						"spec": knownvalue.MapExact(map[string]knownvalue.Check{
+							"template": knownvalue.MapExact(map[string]knownvalue.Check{
+								"spec": knownvalue.MapExact(map[string]knownvalue.Check{
+									"containers": knownvalue.ListExact([]knownvalue.Check{
+										knownvalue.MapExact(map[string]knownvalue.Check{
+											"name":  knownvalue.StringExact("app"),
+											"image": knownvalue.StringExact("app:1.1"),
+											"env": knownvalue.ListExact([]knownvalue.Check{
+												knownvalue.MapExact(map[string]knownvalue.Check{
+													"name":  knownvalue.StringExact("DEBUG"),
+													"value": knownvalue.StringExact("true"),
+												}),
+											}),
+										}),
+									}),
+								}),
+							}),
+						}),
+					}),
+				),
+			},
+		},
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.deployment", "local.patch"}, `{ profile = "helm", append_list = true }`) + `
+					}
+				`,
+			ConfigStateChecks: []statecheck.StateCheck{
+				statecheck.ExpectKnownOutputValue("test",
+					knownvalue.MapPartial(map[string]knownvalue.Check{
+						"spec": knownvalue.MapPartial(map[string]knownvalue.Check{
+							"template": knownvalue.MapPartial(map[string]knownvalue.Check{
+								"spec": knownvalue.MapPartial(map[string]knownvalue.Check{
+									"containers": knownvalue.ListSizeExact(3),
+								}),
+							}),
+						}),
+					}),
+				),
+			},
+		},
+		{
+			Config: locals + `
+					output "test" {
+						value = ` + providerFunctionCall(cfg, []string{"local.deployment", "local.patch"}, `{ profile = "kustomize" }`) + `
+					}
+				`,
+			ExpectError: regexp.MustCompile(`profile must be one of`),
+		},
+	}
+}
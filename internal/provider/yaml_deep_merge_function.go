@@ -8,15 +8,15 @@ import (
 	_ "embed"
 	"fmt"
 	"reflect"
-	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/lablabs/terraform-provider-lara-utils/internal/deepmerge"
 	"github.com/lablabs/terraform-provider-lara-utils/internal/helpers"
-	"sigs.k8s.io/yaml"
+	"go.yaml.in/yaml/v3"
 )
 
 var (
@@ -27,6 +27,11 @@ var (
 
 type YamlDeepMergeFunction struct {
 	DeepMergeFunction
+
+	// output holds the settings of the yaml option, decoded by Run
+	output deepmerge.YamlOptions
+	// sources are the nodes of the merged documents, set by Run when the source layout is preserved
+	sources []*yaml.Node
 }
 
 func NewYamlDeepMergeFunction() function.Function {
@@ -42,7 +47,23 @@ func (fn YamlDeepMergeFunction) Definition(_ context.Context, _ function.Definit
 }
 
 func (fn YamlDeepMergeFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
-	deepmerge.Run(ctx, req, resp, fn)
+	// the documents and options are decoded once, as the layout of the result depends on them too
+	objs, docs, err := fn.getMergingDocuments(ctx, req.Arguments)
+	if resp.Error = function.ConcatFuncErrors(err); resp.Error != nil {
+		return
+	}
+
+	opts, err := fn.GetMergingOptions(ctx, req.Arguments)
+	if resp.Error = function.ConcatFuncErrors(err); resp.Error != nil {
+		return
+	}
+
+	fn.output = opts.Yaml
+	if fn.output.PreserveSource {
+		fn.sources = docs
+	}
+
+	deepmerge.RunMerge(ctx, resp, fn, objs, nil, *opts)
 }
 
 func (fn YamlDeepMergeFunction) FunctionSummary() string {
@@ -64,40 +85,54 @@ func (fn YamlDeepMergeFunction) FunctionObjectsParameter() function.Parameter {
 }
 
 func (r YamlDeepMergeFunction) GetMergingObjects(ctx context.Context, args function.ArgumentsData) ([]map[string]any, *function.FuncError) {
+	objs, _, err := r.getMergingDocuments(ctx, args)
+	return objs, err
+}
+
+// getMergingDocuments decodes the YAML strings into the objects to merge and the nodes of their documents.
+func (r YamlDeepMergeFunction) getMergingDocuments(ctx context.Context, args function.ArgumentsData) ([]map[string]any, []*yaml.Node, *function.FuncError) {
 	arg := basetypes.ListValue{}
 	if err := args.GetArgument(ctx, 0, &arg); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	objs := []map[string]any{}
+	docs := []*yaml.Node{}
 	for idx, elem := range arg.Elements() {
 		val, err := helpers.EncodeValue(elem)
 		if err != nil {
-			return nil, function.NewArgumentFuncError(int64(0), err.Error())
+			return nil, nil, function.NewArgumentFuncError(int64(0), err.Error())
 		}
 		if _, ok := val.(string); !ok {
-			return nil, function.NewArgumentFuncError(int64(0), fmt.Sprintf("merging argument %d must be string, got: %s", idx+1, reflect.TypeOf(val)))
+			return nil, nil, function.NewArgumentFuncError(int64(0), fmt.Sprintf("merging argument %d must be string, got: %s", idx+1, reflect.TypeOf(val)))
 		}
 
-		var obj map[string]any
-		if err := yaml.Unmarshal([]byte(val.(string)), &obj); err != nil { //nolint:forcetypeassert
-			return nil, function.NewArgumentFuncError(int64(0), strings.ReplaceAll(err.Error(), "JSON", "YAML")) // sigs.k8s.io/yaml.Unmarshal returns JSON-related error messages
+		obj, doc, err := helpers.UnmarshalYAMLDocument([]byte(val.(string))) //nolint:forcetypeassert
+		if err != nil {
+			return nil, nil, function.NewArgumentFuncError(int64(0), err.Error())
 		}
 
 		objs = append(objs, obj)
+		docs = append(docs, doc)
 	}
 
-	return objs, nil
+	return objs, docs, nil
 }
 
-func (fn YamlDeepMergeFunction) FunctionResult(ctx context.Context, merged map[string]any) (basetypes.DynamicValue, diag.Diagnostics) {
-	diags := diag.Diagnostics{}
+// GetMergingTypes returns no types, the merged YAML documents are untyped.
+func (fn YamlDeepMergeFunction) GetMergingTypes(context.Context, function.ArgumentsData) ([]attr.Type, *function.FuncError) {
+	return nil, nil
+}
 
-	if len(merged) == 0 {
-		return types.DynamicValue(types.StringValue("")), diags
-	}
+// GetMergingOptions decodes the merging options, including the yaml option accepted only by this function.
+func (fn YamlDeepMergeFunction) GetMergingOptions(ctx context.Context, args function.ArgumentsData) (*deepmerge.DeepMergeOptions, *function.FuncError) {
+	return decodeMergingOptions(ctx, args, "yaml_deep_merge")
+}
+
+func (fn YamlDeepMergeFunction) FunctionResult(ctx context.Context, merged map[string]any, _ []attr.Type) (basetypes.DynamicValue, diag.Diagnostics) {
+	diags := diag.Diagnostics{}
 
-	value, err := yaml.Marshal(merged)
+	value, err := helpers.MarshalYAMLFrom(merged, fn.sources, fn.output.YAMLFormat)
 	if err != nil {
 		diags.Append(diag.NewErrorDiagnostic("error marshaling merged result to YAML", err.Error()))
 	}
@@ -78,6 +78,156 @@ func TestYamlDeepMergeFunction_UnionLists(t *testing.T) {
 	})
 }
 
+func TestYamlDeepMergeFunction_MergeKey(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_MergeKey(testdata.NewDeepMergeTestOptions(testdata.WithYaml())),
+	})
+}
+
+func TestYamlDeepMergeFunction_Paths(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_Paths(testdata.NewDeepMergeTestOptions(testdata.WithYaml())),
+	})
+}
+
+func TestYamlDeepMergeFunction_Directives(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_Directives(testdata.NewDeepMergeTestOptions(testdata.WithYaml())),
+	})
+}
+
+func TestYamlDeepMergeFunction_OnConflict(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_OnConflict(testdata.NewDeepMergeTestOptions(testdata.WithYaml())),
+	})
+}
+
+func TestYamlDeepMergeFunction_TypeMismatch(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_TypeMismatch(testdata.NewDeepMergeTestOptions(testdata.WithYaml())),
+	})
+}
+
+func TestYamlDeepMergeFunction_NullDelete(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_NullDelete(testdata.NewDeepMergeTestOptions(testdata.WithYaml())),
+	})
+}
+
+func TestYamlDeepMergeFunction_Atomic(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_Atomic(testdata.NewDeepMergeTestOptions(testdata.WithYaml())),
+	})
+}
+
+func TestYamlDeepMergeFunction_PrependList(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_PrependList(testdata.NewDeepMergeTestOptions(testdata.WithYaml())),
+	})
+}
+
+func TestYamlDeepMergeFunction_MergeByIndex(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_MergeByIndex(testdata.NewDeepMergeTestOptions(testdata.WithYaml())),
+	})
+}
+
+func TestYamlDeepMergeFunction_UnionBy(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_UnionBy(testdata.NewDeepMergeTestOptions(testdata.WithYaml())),
+	})
+}
+
+func TestYamlDeepMergeFunction_SortLists(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_SortLists(testdata.NewDeepMergeTestOptions(testdata.WithYaml())),
+	})
+}
+
+func TestYamlDeepMergeFunction_Options(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_Options(testdata.NewDeepMergeTestOptions(testdata.WithYaml())),
+	})
+}
+
+func TestYamlDeepMergeFunction_Profiles(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_Profiles(testdata.NewDeepMergeTestOptions(testdata.WithYaml())),
+	})
+}
+
+func TestYamlDeepMergeFunction_PathExpressions(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_PathExpressions(testdata.NewDeepMergeTestOptions(testdata.WithYaml())),
+	})
+}
+
+func TestYamlDeepMergeFunction_LargeNumbers(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    testdata.TestDeepMergeFunction_LargeNumbers(testdata.NewDeepMergeTestOptions(testdata.WithYaml())),
+	})
+}
+
 func TestYamlDeepMergeFunction_Null(t *testing.T) {
 	resource.UnitTest(t, resource.TestCase{
 		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
@@ -141,6 +291,147 @@ func TestYamlDeepMergeFunction_Null(t *testing.T) {
 	})
 }
 
+func TestYamlDeepMergeFunction_Format(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					locals {
+						base = yamlencode({
+							name        = "web"
+							description = "a long description which doesn't fit on a single line"
+							ports       = [80]
+							hosts       = [{ host = "example.com" }]
+						})
+						overlay = yamlencode({
+							ports = [443]
+						})
+					}
+
+					output "test" {
+						value = provider::lara-utils::yaml_deep_merge([local.base, local.overlay], {
+							append_list = true
+							yaml = {
+								indent           = 4
+								indent_sequences = true
+								line_width       = 40
+								flow_lists       = 3
+								quote_style      = "double"
+								quote_strings    = true
+								document_start   = true
+							}
+						})
+					}
+				`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.StringExact(`---
+description: "a long description which
+    doesn't fit on a single line"
+hosts:
+    - host: "example.com"
+name: "web"
+ports: [80, 443]
+`)),
+				},
+			},
+			{
+				Config: `
+					output "test" {
+						value = provider::lara-utils::yaml_deep_merge([], { yaml = { empty_object = true } })
+					}
+				`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.StringExact("{}\n")),
+				},
+			},
+			{
+				Config: `
+					output "test" {
+						value = provider::lara-utils::yaml_deep_merge([], { yaml = { indent = 1 } })
+					}
+				`,
+				ExpectError: regexp.MustCompile(`yaml.indent must be between 2 and 9, got: 1`),
+			},
+			{
+				Config: `
+					output "test" {
+						value = provider::lara-utils::yaml_deep_merge([], { yaml = { quote_style = "backtick" } })
+					}
+				`,
+				ExpectError: regexp.MustCompile(`yaml.quote_style must be one of "single" or "double", got: "backtick"`),
+			},
+			{
+				Config: `
+					output "test" {
+						value = provider::lara-utils::yaml_deep_merge([], { yaml = { width = 80 } })
+					}
+				`,
+				ExpectError: regexp.MustCompile(`unknown option "yaml.width"`),
+			},
+			{
+				Config: `
+					output "test" {
+						value = provider::lara-utils::deep_merge([], { yaml = { indent = 4 } })
+					}
+				`,
+				ExpectError: regexp.MustCompile(`unknown option "yaml"`),
+			},
+		},
+	})
+}
+
+func TestYamlDeepMergeFunction_PreserveSource(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					locals {
+						base = <<-EOT
+							# Chart values
+							replicas: 1 # scaled by HPA
+							image:
+							  tag: '1.25' # pinned
+							  repository: nginx
+							EOT
+						overlay = <<-EOT
+							image:
+							  tag: '1.26'
+							# extra labels
+							labels:
+							  team: web
+							EOT
+					}
+
+					output "test" {
+						value = provider::lara-utils::yaml_deep_merge([local.base, local.overlay], {
+							yaml = { preserve_source = true }
+						})
+					}
+				`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.StringExact(`# Chart values
+replicas: 1 # scaled by HPA
+image:
+  tag: '1.26' # pinned
+  repository: nginx
+# extra labels
+labels:
+  team: web
+`)),
+				},
+			},
+		},
+	})
+}
+
 func TestYamlDeepMergeFunction_InvalidType(t *testing.T) {
 	resource.UnitTest(t, resource.TestCase{
 		TerraformVersionChecks: []tfversion.TerraformVersionCheck{